@@ -1,9 +1,11 @@
 package blockchain
 
 import (
+	"sort"
 	"sync"
 
 	"backend/errors"
+	"backend/util"
 )
 
 // Account represents an account within the accountModel.
@@ -13,65 +15,119 @@ type Account struct {
 	Transactions uint64
 }
 
-// accountModel holds the accounts of all keys.
+// accountModel holds the accounts of all keys, the funds held in escrow, the
+// deployed contracts with the receipts of their executions, the registered names,
+// the stakes bonded to validators along with their consensus keys, and the stakes
+// that are being unbonded. The elected stakes are the stakes at the
+// start of the current epoch, and make up the validator set of that epoch; jailed
+// validators are excluded from it until their epoch of release has passed, and
+// validators jailed for their downtime until they unjail themselves. Burned
+// funds, such as slashed stake, are taken out of circulation. The beacon holds the
+// seed that elects the proposers of the current epoch. The proposals hold the changes
+// to the protocol parameters that have been submitted, and the parameters hold the
+// values of the changes that passed.
 type accountModel struct {
 	sync.RWMutex
-	accounts map[string]*Account
+	accounts   map[string]*Account
+	escrows    map[string]Escrow
+	contracts  map[string]*SmartContract
+	receipts   map[string]Receipt
+	names      map[string]Name
+	bonds      map[bondKey]Coin
+	unbonding  map[unbondKey]Coin
+	keys       map[string]string
+	stakes     map[string]Coin
+	elected    map[string]Coin
+	epoch      uint64
+	jailed     map[string]uint64
+	punished   map[string]struct{}
+	liveness   map[string]Liveness
+	beacon     Beacon
+	proposals  map[string]Proposal
+	parameters map[string]float64
+	burned     Coin
 }
 
 // newAccountModel creates a new accountModel.
 func newAccountModel() *accountModel {
 	return &accountModel{
-		accounts: make(map[string]*Account),
+		accounts:   make(map[string]*Account),
+		escrows:    make(map[string]Escrow),
+		contracts:  make(map[string]*SmartContract),
+		receipts:   make(map[string]Receipt),
+		names:      make(map[string]Name),
+		bonds:      make(map[bondKey]Coin),
+		unbonding:  make(map[unbondKey]Coin),
+		keys:       make(map[string]string),
+		stakes:     make(map[string]Coin),
+		elected:    make(map[string]Coin),
+		jailed:     make(map[string]uint64),
+		punished:   make(map[string]struct{}),
+		liveness:   make(map[string]Liveness),
+		beacon:     newBeacon(),
+		proposals:  make(map[string]Proposal),
+		parameters: make(map[string]float64),
+		burned:     ToCoin(0),
 	}
 }
 
 // fromBlocks generates the balances from every block.
-// Method should only be called on blockchain initialization.
-// Assumption is that every block within the blockchain are valid.
-// That also means that all transactions in a block are valid.
+// Method does not validate the transactions; blocks should be validated
+// beforehand by using validateBlocks.
 func (am *accountModel) fromBlocks(block ...Block) {
-	var wg sync.WaitGroup
-
 	for _, b := range block {
-		wg.Add(1)
-
-		go func() {
-			defer wg.Done()
-
-			for _, transaction := range b.Transactions {
-				am.Lock()
-
-				tx := am.accounts[transaction.Sender]
-				rx := am.accounts[transaction.Receiver]
-
-				if tx != nil {
-					tx.Balance = tx.Balance.Sub(transaction.Amount)
-					tx.Transactions++
-				} else {
-					// this should not happen.
-					// sender should always exist; default balance is zero.
-					// transaction should be verified before its being forged into a block.
-					am.accounts[transaction.Sender] = &Account{
-						Balance:      ToCoin(0),
-						Transactions: 1,
-					}
-				}
-
-				if rx != nil {
-					rx.Balance = rx.Balance.Add(transaction.Amount)
-				} else {
-					am.accounts[transaction.Receiver] = &Account{
-						Balance:      ToCoin(transaction.Amount),
-						Transactions: 0,
-					}
-				}
-
-				am.Unlock()
+		for _, transaction := range b.Transactions {
+			am.transfer(transaction)
+		}
+	}
+}
+
+// transfer applies the given transaction without validating it.
+// A stake is bonded to its validator instead of being credited to the receiver.
+func (am *accountModel) transfer(transaction Transaction) {
+	am.Lock()
+	defer am.Unlock()
+
+	// unbonded stake returns to the sender
+	amount := transaction.Amount
+	if transaction.Type == Unstake {
+		amount = -amount
+	}
+
+	tx := am.accounts[transaction.Sender]
+
+	if tx != nil {
+		tx.Balance = tx.Balance.Sub(amount)
+		tx.Transactions++
+	} else {
+		// this should not happen, except for genesis.
+		// sender should always exist; default balance is zero.
+		// transaction should be verified before its being forged into a block.
+		am.accounts[transaction.Sender] = &Account{
+			Balance:      ToCoin(0),
+			Transactions: 1,
+		}
+	}
+
+	if transaction.Type == Stake || transaction.Type == Unstake {
+		if bond, err := transaction.bond(); err == nil {
+			am.addBond(transaction.Sender, bond.Validator, amount)
+
+			if _, ok := am.keys[bond.Validator]; !ok && len(bond.Key) > 0 {
+				am.keys[bond.Validator] = bond.Key
 			}
-		}()
+		}
+
+		return
+	}
 
-		wg.Wait()
+	if rx := am.accounts[transaction.Receiver]; rx != nil {
+		rx.Balance = rx.Balance.Add(transaction.Amount)
+	} else {
+		am.accounts[transaction.Receiver] = &Account{
+			Balance:      ToCoin(transaction.Amount),
+			Transactions: 0,
+		}
 	}
 }
 
@@ -130,22 +186,719 @@ func (am *accountModel) add(key string, balance float64) error {
 	return nil
 }
 
-// update updates the balance of the given key.
-func (am *accountModel) update(key string, amount float64) error {
-	if !am.exists(key) {
-		return errors.ErrInvalidOperation("key does not exist")
+// modify adds the amount to the balance of the given key, and increments the
+// transactions done by the account if nonce is set. The account is created if it
+// does not exist; in which case true is returned.
+// Changes should be made through a journal.
+func (am *accountModel) modify(key string, amount float64, nonce bool) bool {
+	am.Lock()
+	defer am.Unlock()
+
+	account, ok := am.accounts[key]
+	if !ok {
+		account = &Account{Balance: ToCoin(0)}
+		am.accounts[key] = account
 	}
 
-	// this should not happen
-	if 0 > am.accounts[key].Balance.Float64()+amount {
-		return errors.ErrInvalidOperation("balance cannot be negative")
+	account.Balance = account.Balance.Add(amount)
+
+	if nonce {
+		account.Transactions++
+	}
+
+	return !ok
+}
+
+// getEscrow returns the Escrow associated with given id.
+func (am *accountModel) getEscrow(id string) (Escrow, error) {
+	am.RLock()
+	defer am.RUnlock()
+
+	escrow, ok := am.escrows[id]
+	if !ok {
+		return Escrow{}, errors.ErrInvalidOperation("escrow does not exist")
 	}
 
+	return escrow, nil
+}
+
+// open opens a new Escrow with the given id.
+// Changes should be made through a journal.
+func (am *accountModel) open(id string, escrow Escrow) {
 	am.Lock()
 	defer am.Unlock()
 
-	am.accounts[key].Balance = am.accounts[key].Balance.Add(amount)
-	am.accounts[key].Transactions++
+	am.escrows[id] = escrow
+}
 
-	return nil
+// settle removes the Escrow with the given id, and returns it.
+// Changes should be made through a journal.
+func (am *accountModel) settle(id string) (Escrow, error) {
+	am.Lock()
+	defer am.Unlock()
+
+	escrow, ok := am.escrows[id]
+	if !ok {
+		return Escrow{}, errors.ErrInvalidOperation("escrow does not exist")
+	}
+
+	delete(am.escrows, id)
+
+	return escrow, nil
+}
+
+// getContract returns a copy of the SmartContract at the given address.
+func (am *accountModel) getContract(address string) (SmartContract, error) {
+	am.RLock()
+	defer am.RUnlock()
+
+	contract, ok := am.contracts[address]
+	if !ok {
+		return SmartContract{}, errors.ErrInvalidOperation("contract does not exist")
+	}
+
+	c := *contract
+	c.Storage = make(map[int64]int64, len(contract.Storage))
+
+	for k, v := range contract.Storage {
+		c.Storage[k] = v
+	}
+
+	return c, nil
+}
+
+// deploy adds the SmartContract at the given address.
+// Changes should be made through a journal.
+func (am *accountModel) deploy(address string, contract *SmartContract) {
+	am.Lock()
+	defer am.Unlock()
+
+	am.contracts[address] = contract
+}
+
+// load reads a value from the storage of the SmartContract at the given address.
+func (am *accountModel) load(address string, slot int64) int64 {
+	am.RLock()
+	defer am.RUnlock()
+
+	if contract, ok := am.contracts[address]; ok {
+		return contract.Storage[slot]
+	}
+
+	return 0
+}
+
+// store writes a value to the storage of the SmartContract at the given address,
+// and returns the previous value and whether it existed.
+// Changes should be made through a journal.
+func (am *accountModel) store(address string, slot int64, value int64) (int64, bool) {
+	am.Lock()
+	defer am.Unlock()
+
+	contract, ok := am.contracts[address]
+	if !ok {
+		return 0, false
+	}
+
+	prev, existed := contract.Storage[slot]
+	contract.Storage[slot] = value
+
+	return prev, existed
+}
+
+// getReceipt returns the Receipt of the transaction with the given hash.
+func (am *accountModel) getReceipt(hash string) (Receipt, error) {
+	am.RLock()
+	defer am.RUnlock()
+
+	receipt, ok := am.receipts[hash]
+	if !ok {
+		return Receipt{}, errors.ErrInvalidOperation("receipt does not exist")
+	}
+
+	return receipt, nil
+}
+
+// addReceipt adds the Receipt of the transaction with the given hash.
+// Changes should be made through a journal.
+func (am *accountModel) addReceipt(hash string, receipt Receipt) {
+	am.Lock()
+	defer am.Unlock()
+
+	am.receipts[hash] = receipt
+}
+
+// getName returns the registration of the given name; the registration may have expired.
+func (am *accountModel) getName(key string) (Name, error) {
+	am.RLock()
+	defer am.RUnlock()
+
+	name, ok := am.names[key]
+	if !ok {
+		return Name{}, errors.ErrInvalidOperation("name does not exist")
+	}
+
+	return name, nil
+}
+
+// setName sets the registration of the given name, and returns the previous
+// registration and whether it existed.
+// Changes should be made through a journal.
+func (am *accountModel) setName(key string, name Name) (Name, bool) {
+	am.Lock()
+	defer am.Unlock()
+
+	prev, existed := am.names[key]
+	am.names[key] = name
+
+	return prev, existed
+}
+
+// getBond returns the stake the delegator has bonded to the validator.
+func (am *accountModel) getBond(delegator string, validator string) Coin {
+	am.RLock()
+	defer am.RUnlock()
+
+	if stake, ok := am.bonds[bondKey{delegator, validator}]; ok {
+		return stake
+	}
+
+	return ToCoin(0)
+}
+
+// keyOf returns the registered consensus key of the validator.
+func (am *accountModel) keyOf(validator string) (string, bool) {
+	am.RLock()
+	defer am.RUnlock()
+
+	key, ok := am.keys[validator]
+
+	return key, ok
+}
+
+// registerKey registers the consensus key of the validator.
+// Changes should be made through a journal.
+func (am *accountModel) registerKey(validator string, key string) {
+	am.Lock()
+	defer am.Unlock()
+
+	am.keys[validator] = key
+}
+
+// validators returns the stake of every validator with a stake.
+func (am *accountModel) validators() map[string]Coin {
+	am.RLock()
+	defer am.RUnlock()
+
+	return copyStakes(am.stakes)
+}
+
+// validatorsAt returns the validator set of the given epoch; the current stakes
+// become the validator set once a new epoch starts. Jailed validators are excluded.
+func (am *accountModel) validatorsAt(epoch uint64) map[string]Coin {
+	am.RLock()
+	defer am.RUnlock()
+
+	stakes := am.elected
+	if epoch > am.epoch {
+		stakes = am.stakes
+	}
+
+	validators := copyStakes(stakes)
+
+	for v := range validators {
+		if until, ok := am.jailed[v]; ok && until >= epoch {
+			delete(validators, v)
+		}
+
+		if am.liveness[v].Jailed {
+			delete(validators, v)
+		}
+	}
+
+	return validators
+}
+
+// delegators returns the delegators that have bonded stake to the validator, in order.
+func (am *accountModel) delegators(validator string) []string {
+	am.RLock()
+	defer am.RUnlock()
+
+	delegators := make([]string, 0)
+
+	for k := range am.bonds {
+		if k.validator == validator {
+			delegators = append(delegators, k.delegator)
+		}
+	}
+
+	sort.Strings(delegators)
+
+	return delegators
+}
+
+// isPunished checks whether the validator has been punished for the proposal with the given key.
+func (am *accountModel) isPunished(key string) bool {
+	am.RLock()
+	defer am.RUnlock()
+
+	_, ok := am.punished[key]
+
+	return ok
+}
+
+// punish records that the validator has been punished for the proposal with the given key.
+// Changes should be made through a journal.
+func (am *accountModel) punish(key string) {
+	am.Lock()
+	defer am.Unlock()
+
+	am.punished[key] = struct{}{}
+}
+
+// jail jails the validator until the given epoch, and returns the epoch it was jailed
+// until before, if it was jailed. Changes should be made through a journal.
+func (am *accountModel) jail(validator string, until uint64) (uint64, bool) {
+	am.Lock()
+	defer am.Unlock()
+
+	prev, ok := am.jailed[validator]
+	am.jailed[validator] = until
+
+	return prev, ok
+}
+
+// jailedUntil returns the epoch the validator is jailed until, if it is jailed.
+func (am *accountModel) jailedUntil(validator string) (uint64, bool) {
+	am.RLock()
+	defer am.RUnlock()
+
+	until, ok := am.jailed[validator]
+
+	return until, ok
+}
+
+// livenessOf returns the liveness of the validator, if it has been tracked.
+func (am *accountModel) livenessOf(validator string) (Liveness, bool) {
+	am.RLock()
+	defer am.RUnlock()
+
+	l, ok := am.liveness[validator]
+
+	return l, ok
+}
+
+// livenesses returns the liveness of every validator that has been tracked.
+func (am *accountModel) livenesses() map[string]Liveness {
+	am.RLock()
+	defer am.RUnlock()
+
+	c := make(map[string]Liveness, len(am.liveness))
+
+	for k, v := range am.liveness {
+		c[k] = v
+	}
+
+	return c
+}
+
+// setLiveness sets the liveness of the validator, and returns its previous liveness,
+// if it had been tracked. Changes should be made through a journal.
+func (am *accountModel) setLiveness(validator string, l Liveness) (Liveness, bool) {
+	am.Lock()
+	defer am.Unlock()
+
+	prev, ok := am.liveness[validator]
+	am.liveness[validator] = l
+
+	return prev, ok
+}
+
+// currentEpoch returns the current epoch.
+func (am *accountModel) currentEpoch() uint64 {
+	am.RLock()
+	defer am.RUnlock()
+
+	return am.epoch
+}
+
+// advance starts the given epoch with the current stakes as its validator set, and
+// the next beacon. It returns the previous epoch, its validator set and its beacon.
+// Changes should be made through a journal.
+func (am *accountModel) advance(epoch uint64) (uint64, map[string]Coin, Beacon) {
+	am.Lock()
+	defer am.Unlock()
+
+	prev, elected, beacon := am.epoch, am.elected, am.beacon
+
+	am.epoch = epoch
+	am.elected = copyStakes(am.stakes)
+	am.beacon = beacon.next(epoch)
+
+	return prev, elected, beacon
+}
+
+// getBeacon returns a copy of the beacon of the current epoch.
+func (am *accountModel) getBeacon() Beacon {
+	am.RLock()
+	defer am.RUnlock()
+
+	return am.beacon.clone()
+}
+
+// beaconAt returns the beacon of the given epoch; the beacon of the next epoch is
+// derived from the current beacon once the epoch starts.
+func (am *accountModel) beaconAt(epoch uint64) Beacon {
+	am.RLock()
+	defer am.RUnlock()
+
+	if epoch > am.epoch {
+		return am.beacon.next(epoch)
+	}
+
+	return am.beacon
+}
+
+// commitSecret records the commitment of the validator within the current epoch.
+// Changes should be made through a journal.
+func (am *accountModel) commitSecret(validator string, hash string) {
+	am.Lock()
+	defer am.Unlock()
+
+	am.beacon.Commitments[validator] = hash
+}
+
+// revealSecret records the secret the validator revealed within the current epoch, and
+// mixes it into the seed of the next epoch. Changes should be made through a journal.
+func (am *accountModel) revealSecret(validator string, secret string) {
+	am.Lock()
+	defer am.Unlock()
+
+	am.beacon.Revealed[validator] = secret
+	am.beacon.mix(util.HexDecode(secret))
+}
+
+// getProposal returns a copy of the proposal with the given id, if it has been submitted.
+func (am *accountModel) getProposal(id string) (Proposal, bool) {
+	am.RLock()
+	defer am.RUnlock()
+
+	p, ok := am.proposals[id]
+	if ok {
+		p.Votes = copyVotes(p.Votes)
+	}
+
+	return p, ok
+}
+
+// getProposals returns a copy of every proposal that has been submitted.
+func (am *accountModel) getProposals() map[string]Proposal {
+	am.RLock()
+	defer am.RUnlock()
+
+	c := make(map[string]Proposal, len(am.proposals))
+
+	for id, p := range am.proposals {
+		p.Votes = copyVotes(p.Votes)
+		c[id] = p
+	}
+
+	return c
+}
+
+// copyVotes returns a copy of the votes.
+func copyVotes(votes map[string]bool) map[string]bool {
+	c := make(map[string]bool, len(votes))
+
+	for k, v := range votes {
+		c[k] = v
+	}
+
+	return c
+}
+
+// activated returns the proposals that are activated at the given height, in order.
+func (am *accountModel) activated(height uint64) []string {
+	am.RLock()
+	defer am.RUnlock()
+
+	ids := make([]string, 0)
+
+	for _, id := range sortedKeys(am.proposals) {
+		if am.proposals[id].Activation == height {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// submit adds the proposal with the given id. Changes should be made through a journal.
+func (am *accountModel) submit(id string, p Proposal) {
+	am.Lock()
+	defer am.Unlock()
+
+	am.proposals[id] = p
+}
+
+// cast records the vote of the validator on the proposal with the given id.
+// Changes should be made through a journal.
+func (am *accountModel) cast(id string, validator string, approve bool) {
+	am.Lock()
+	defer am.Unlock()
+
+	am.proposals[id].Votes[validator] = approve
+}
+
+// getParameters returns a copy of the values of the parameters that have been changed.
+func (am *accountModel) getParameters() map[string]float64 {
+	am.RLock()
+	defer am.RUnlock()
+
+	c := make(map[string]float64, len(am.parameters))
+
+	for k, v := range am.parameters {
+		c[k] = v
+	}
+
+	return c
+}
+
+// setParameter changes the value of the parameter, and returns its previous value, if
+// it had been changed before. Changes should be made through a journal.
+func (am *accountModel) setParameter(name string, value float64) (float64, bool) {
+	am.Lock()
+	defer am.Unlock()
+
+	prev, ok := am.parameters[name]
+	am.parameters[name] = value
+
+	return prev, ok
+}
+
+// burn takes the amount out of circulation. Changes should be made through a journal.
+func (am *accountModel) burn(amount float64) {
+	am.Lock()
+	defer am.Unlock()
+
+	am.burned = am.burned.Add(amount)
+}
+
+// supply returns the funds held by accounts, bonded or being unbonded as stake, or held
+// in escrow, and the funds that have been burned.
+func (am *accountModel) supply() (Coin, Coin) {
+	am.RLock()
+	defer am.RUnlock()
+
+	supply := ToCoin(0)
+
+	for _, a := range am.accounts {
+		supply = Coin{supply.decimal.Add(a.Balance.decimal)}
+	}
+
+	for _, stake := range am.bonds {
+		supply = Coin{supply.decimal.Add(stake.decimal)}
+	}
+
+	for _, stake := range am.unbonding {
+		supply = Coin{supply.decimal.Add(stake.decimal)}
+	}
+
+	for _, e := range am.escrows {
+		supply = supply.Add(e.Amount)
+	}
+
+	return supply, am.burned
+}
+
+// copyStakes returns a copy of the stakes.
+func copyStakes(stakes map[string]Coin) map[string]Coin {
+	c := make(map[string]Coin, len(stakes))
+
+	for k, v := range stakes {
+		c[k] = v
+	}
+
+	return c
+}
+
+// bond adds the amount to the stake the delegator has bonded to the validator.
+// Changes should be made through a journal.
+func (am *accountModel) bond(delegator string, validator string, amount float64) {
+	am.Lock()
+	defer am.Unlock()
+
+	am.addBond(delegator, validator, amount)
+}
+
+// unbond adds the amount to the stake that is being unbonded; stakes that become zero
+// are removed. Changes should be made through a journal.
+func (am *accountModel) unbond(k unbondKey, amount float64) {
+	am.Lock()
+	defer am.Unlock()
+
+	am.addUnbonding(k, amount)
+}
+
+// addUnbonding adds the amount to the stake that is being unbonded.
+func (am *accountModel) addUnbonding(k unbondKey, amount float64) {
+	if am.unbonding[k] = am.unbonding[k].Add(amount); am.unbonding[k].Equal(ToCoin(0)) {
+		delete(am.unbonding, k)
+	}
+}
+
+// getUnbonding returns the stake that is being unbonded.
+func (am *accountModel) getUnbonding(k unbondKey) Coin {
+	am.RLock()
+	defer am.RUnlock()
+
+	if stake, ok := am.unbonding[k]; ok {
+		return stake
+	}
+
+	return ToCoin(0)
+}
+
+// unbondingFrom returns the stakes that are being unbonded from the validator, in order.
+func (am *accountModel) unbondingFrom(validator string) []unbondKey {
+	return am.unbondings(func(k unbondKey) bool { return k.validator == validator })
+}
+
+// releasable returns the stakes that are released in the given epoch or before, in order.
+func (am *accountModel) releasable(epoch uint64) []unbondKey {
+	return am.unbondings(func(k unbondKey) bool { return epoch >= k.release })
+}
+
+// unbondings returns the stakes that are being unbonded that match, ordered by
+// delegator, validator and release.
+func (am *accountModel) unbondings(match func(k unbondKey) bool) []unbondKey {
+	am.RLock()
+	defer am.RUnlock()
+
+	keys := make([]unbondKey, 0)
+
+	for k := range am.unbonding {
+		if match(k) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].delegator != keys[j].delegator {
+			return keys[i].delegator < keys[j].delegator
+		}
+
+		if keys[i].validator != keys[j].validator {
+			return keys[i].validator < keys[j].validator
+		}
+
+		return keys[i].release < keys[j].release
+	})
+
+	return keys
+}
+
+// addBond adds the amount to the bond and the stake of the validator; bonds and
+// stakes that become zero are removed.
+func (am *accountModel) addBond(delegator string, validator string, amount float64) {
+	key := bondKey{delegator, validator}
+	zero := ToCoin(0)
+
+	if am.bonds[key] = am.bonds[key].Add(amount); am.bonds[key].Equal(zero) {
+		delete(am.bonds, key)
+	}
+
+	if am.stakes[validator] = am.stakes[validator].Add(amount); am.stakes[validator].Equal(zero) {
+		delete(am.stakes, validator)
+	}
+}
+
+// revert reverts a singular change made to the accountModel.
+func (am *accountModel) revert(c change) {
+	am.Lock()
+	defer am.Unlock()
+
+	switch c.kind {
+	case escrowOpened:
+		delete(am.escrows, c.key)
+	case escrowSettled:
+		am.escrows[c.key] = c.escrow
+	case contractDeployed:
+		delete(am.contracts, c.key)
+	case storageWritten:
+		if contract, ok := am.contracts[c.key]; ok {
+			if c.created {
+				delete(contract.Storage, c.slot)
+			} else {
+				contract.Storage[c.slot] = c.value
+			}
+		}
+	case receiptAdded:
+		delete(am.receipts, c.key)
+	case bondChanged:
+		am.addBond(c.key, c.target, -c.amount)
+	case unbondingChanged:
+		am.addUnbonding(unbondKey{c.key, c.target, c.epoch}, -c.amount)
+	case epochChanged:
+		am.epoch, am.elected, am.beacon = c.epoch, c.stakes, c.beacon
+	case secretCommitted:
+		delete(am.beacon.Commitments, c.key)
+	case secretRevealed:
+		delete(am.beacon.Revealed, c.key)
+		am.beacon.mix(util.HexDecode(c.target))
+	case validatorJailed:
+		if c.created {
+			delete(am.jailed, c.key)
+		} else {
+			am.jailed[c.key] = c.epoch
+		}
+	case validatorPunished:
+		delete(am.punished, c.key)
+	case keyRegistered:
+		delete(am.keys, c.key)
+	case livenessChanged:
+		if c.created {
+			delete(am.liveness, c.key)
+		} else {
+			am.liveness[c.key] = c.liveness
+		}
+	case supplyBurned:
+		am.burned = am.burned.Sub(c.amount)
+	case proposalSubmitted:
+		delete(am.proposals, c.key)
+	case ballotCast:
+		delete(am.proposals[c.key].Votes, c.target)
+	case parameterChanged:
+		if c.created {
+			delete(am.parameters, c.key)
+		} else {
+			am.parameters[c.key] = c.amount
+		}
+	case nameChanged:
+		if c.created {
+			delete(am.names, c.key)
+		} else {
+			am.names[c.key] = c.name
+		}
+	case accountChanged:
+		am.revertAccount(c)
+	}
+}
+
+// revertAccount reverts a singular change made to an Account.
+func (am *accountModel) revertAccount(c change) {
+	account, ok := am.accounts[c.key]
+	if !ok {
+		return
+	}
+
+	account.Balance = account.Balance.Sub(c.amount)
+
+	if c.nonce {
+		account.Transactions--
+	}
+
+	if c.created && account.Transactions == 0 && account.Balance.Equal(ToCoin(0)) {
+		delete(am.accounts, c.key)
+	}
 }
@@ -0,0 +1,263 @@
+package blockchain
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"backend/crypto"
+	"backend/util"
+)
+
+// secretSize is the size of a secret of the randomness beacon, in bytes.
+const secretSize = 32
+
+// Secret holds the parameters of a Commitment or Reveal transaction; a validator
+// commits to the hash of its secret, after which it reveals the secret itself.
+type Secret struct {
+	Hash   string `json:"hash,omitempty"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// Beacon is the randomness beacon of an epoch; its seed elects the proposers of the
+// epoch. Validators commit to a secret within an epoch, and reveal it within the next
+// epoch. The XOR of the secrets revealed within an epoch is mixed into the seed of the
+// next epoch, such that no validator can bias the election, other than by withholding
+// its secret; validators that withhold their secret are slashed.
+type Beacon struct {
+	Epoch       uint64            `json:"epoch"`
+	Seed        string            `json:"seed"`
+	Mix         string            `json:"mix"`
+	Commitments map[string]string `json:"commitments"`
+	Committed   map[string]string `json:"committed"`
+	Revealed    map[string]string `json:"revealed"`
+}
+
+// newBeacon creates the Beacon of the first epoch.
+func newBeacon() Beacon {
+	return Beacon{
+		Mix:         util.HexEncode(make([]byte, secretSize)),
+		Commitments: make(map[string]string),
+		Committed:   make(map[string]string),
+		Revealed:    make(map[string]string),
+	}
+}
+
+// mix mixes the secret into the XOR of the revealed secrets; mixing the same secret
+// again removes it.
+func (b *Beacon) mix(secret []byte) {
+	mix := util.HexDecode(b.Mix)
+
+	for i := range mix {
+		mix[i] ^= secret[i]
+	}
+
+	b.Mix = util.HexEncode(mix)
+}
+
+// clone returns a copy of the Beacon that can be modified.
+func (b Beacon) clone() Beacon {
+	c := b
+	c.Commitments = copyStrings(b.Commitments)
+	c.Committed = copyStrings(b.Committed)
+	c.Revealed = copyStrings(b.Revealed)
+
+	return c
+}
+
+// copyStrings returns a copy of the map.
+func copyStrings(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+
+	for k, v := range m {
+		c[k] = v
+	}
+
+	return c
+}
+
+// next returns the Beacon of the given later epoch; its seed mixes the secrets revealed
+// within this epoch into the seed of this epoch. The commitments made within this epoch
+// are to be revealed within the next epoch; they expire when that epoch is skipped.
+func (b Beacon) next(epoch uint64) Beacon {
+	h := sha256.New()
+	h.Write(util.HexDecode(b.Seed))
+	h.Write(util.HexDecode(b.Mix))
+	h.Write(binary.BigEndian.AppendUint64(nil, epoch))
+
+	next := newBeacon()
+	next.Epoch = epoch
+	next.Seed = util.HexEncode(h.Sum(nil))
+
+	if epoch == b.Epoch+1 {
+		next.Committed = copyStrings(b.Commitments)
+	}
+
+	return next
+}
+
+// withholders returns the validators that did not reveal the secret they committed to
+// within the previous epoch, in order.
+func (b Beacon) withholders() []string {
+	withholders := make([]string, 0)
+
+	for v := range b.Committed {
+		if _, ok := b.Revealed[v]; !ok {
+			withholders = append(withholders, v)
+		}
+	}
+
+	sort.Strings(withholders)
+
+	return withholders
+}
+
+// NewSecret derives the secret of the validator for the given epoch from its consensus
+// key. Signatures are deterministic, such that the validator can reveal its secret
+// without storing it, while no one else can derive it.
+func NewSecret(key *ecdsa.PrivateKey, epoch uint64) ([]byte, error) {
+	sig, err := crypto.Sign(key, []byte(fmt.Sprintf("beacon:%d", epoch)))
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.Sum256(sig)
+
+	return h[:], nil
+}
+
+// NewCommitment returns the commitment to the secret; its hash.
+func NewCommitment(secret []byte) Secret {
+	sum := sha256.Sum256(secret)
+
+	return Secret{Hash: util.HexEncode(sum[:])}
+}
+
+// secret decodes the Secret of the transaction.
+func (t Transaction) secret() (Secret, error) {
+	var s Secret
+
+	if err := json.Unmarshal([]byte(t.Data), &s); err != nil {
+		return Secret{}, fmt.Errorf("%w: invalid secret", ErrInvalidTransaction)
+	}
+
+	return s, nil
+}
+
+// committable checks whether the Commitment transaction is valid; the sender should be
+// a validator with a registered consensus key, that did not commit within the epoch yet.
+func (j *journal) committable(transaction Transaction) (Secret, error) {
+	if transaction.Amount != 0 {
+		return Secret{}, fmt.Errorf("%w: amount should be zero", ErrInvalidTransaction)
+	}
+
+	s, err := transaction.secret()
+	if err != nil {
+		return Secret{}, err
+	}
+
+	if len(util.HexDecode(s.Hash)) != sha256.Size {
+		return Secret{}, fmt.Errorf("%w: invalid commitment", ErrInvalidTransaction)
+	}
+
+	if _, ok := j.am.keyOf(transaction.Sender); !ok {
+		return Secret{}, fmt.Errorf("%w: sender is not a validator", ErrInvalidTransaction)
+	}
+
+	if _, ok := j.am.getBeacon().Commitments[transaction.Sender]; ok {
+		return Secret{}, fmt.Errorf("%w: validator has already committed", ErrInvalidTransaction)
+	}
+
+	return s, nil
+}
+
+// commitment applies a Commitment transaction; the commitment is to be revealed
+// within the next epoch.
+func (j *journal) commitment(transaction Transaction) error {
+	s, err := j.committable(transaction)
+	if err != nil {
+		return err
+	}
+
+	j.commitSecret(transaction.Sender, s.Hash)
+	j.modify(transaction.Sender, 0, true)
+
+	return nil
+}
+
+// revealable checks whether the Reveal transaction is valid; the secret should match
+// the commitment the sender made within the previous epoch, and be revealed only once.
+func (j *journal) revealable(transaction Transaction) ([]byte, error) {
+	if transaction.Amount != 0 {
+		return nil, fmt.Errorf("%w: amount should be zero", ErrInvalidTransaction)
+	}
+
+	s, err := transaction.secret()
+	if err != nil {
+		return nil, err
+	}
+
+	secret := util.HexDecode(s.Secret)
+	if len(secret) != secretSize {
+		return nil, fmt.Errorf("%w: invalid secret", ErrInvalidTransaction)
+	}
+
+	b := j.am.getBeacon()
+
+	hash, ok := b.Committed[transaction.Sender]
+	if !ok {
+		return nil, fmt.Errorf("%w: validator has no commitment", ErrInvalidTransaction)
+	}
+
+	if _, ok = b.Revealed[transaction.Sender]; ok {
+		return nil, fmt.Errorf("%w: secret has already been revealed", ErrInvalidTransaction)
+	}
+
+	if NewCommitment(secret).Hash != hash {
+		return nil, fmt.Errorf("%w: secret does not match commitment", ErrInvalidTransaction)
+	}
+
+	return secret, nil
+}
+
+// reveal applies a Reveal transaction; the secret is mixed into the seed of the next epoch.
+func (j *journal) reveal(transaction Transaction) error {
+	secret, err := j.revealable(transaction)
+	if err != nil {
+		return err
+	}
+
+	j.revealSecret(transaction.Sender, util.HexEncode(secret))
+	j.modify(transaction.Sender, 0, true)
+
+	return nil
+}
+
+// Beacon returns the randomness beacon of the current epoch, including the commitments
+// and secrets of pending transactions.
+func (b *Blockchain) Beacon() Beacon {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.am.getBeacon()
+}
+
+// Seed returns the seed that elects the proposers of the given slot; there is no seed
+// before the beacon fork.
+func (b *Blockchain) Seed(slot uint64) []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.schedule.Rules(uint64(len(b.Blocks))).Active(BeaconFork) {
+		return nil
+	}
+
+	// the secrets of pending transactions have been revealed
+	b.release()
+	defer b.reserve()
+
+	return util.HexDecode(b.am.beaconAt(b.clock(b.genesis()).EpochOf(slot)).Seed)
+}
@@ -0,0 +1,86 @@
+package blockchain
+
+import (
+	"crypto/ecdsa"
+	"time"
+
+	"backend/crypto"
+	"backend/util"
+)
+
+// validatorTransaction creates a transaction of the given type, without amount, of the
+// validator of the given key.
+func (suite *ValidationTestSuite) validatorTransaction(key *ecdsa.PrivateKey, nonce uint64, txType TxType, data any) Transaction {
+	return suite.sign(key, Transaction{
+		Sender:    crypto.Address(&key.PublicKey),
+		Nonce:     nonce,
+		Timestamp: time.Now().Unix(),
+		Type:      txType,
+		Data:      string(util.JSONEncode(data)),
+	})
+}
+
+func (suite *ValidationTestSuite) TestBeacon() {
+	key, validator := newValidator(suite.T())
+	other, _ := newValidator(suite.T())
+	nonce := uint64(1)
+
+	// next creates an exchange transaction from genesis with the next nonce
+	next := func() Transaction {
+		nonce++
+
+		return suite.transaction(1, nonce-1)
+	}
+
+	t := next()
+	t.Receiver = ""
+	t.Type = Stake
+	t.Data = string(util.JSONEncode(Bond{Validator: validator, Key: util.HexEncode(crypto.EncodePublicKey(&key.PublicKey))}))
+	t = suite.sign(suite.priv, t)
+
+	suite.forge(t)
+	suite.bc.SetPunishment(Punishment{Withhold: 0.5})
+
+	secret, err := NewSecret(key, 0)
+	suite.Require().NoError(err)
+
+	reveal := suite.validatorTransaction(key, 1, Reveal, Secret{Secret: util.HexEncode(secret)})
+
+	// only validators commit, and secrets are revealed within the next epoch
+	suite.ErrorIs(suite.bc.UpdateMempool(suite.validatorTransaction(other, 0, Commitment, NewCommitment(secret))), ErrInvalidTransaction)
+	suite.forge(suite.validatorTransaction(key, 0, Commitment, NewCommitment(secret)))
+	suite.ErrorIs(suite.bc.UpdateMempool(reveal), ErrInvalidTransaction)
+	suite.Contains(suite.bc.Beacon().Commitments, validator)
+
+	for suite.bc.Last().Slot < defaultEpoch {
+		suite.forge(next())
+	}
+
+	// the secret should match the commitment
+	wrong := suite.validatorTransaction(key, 1, Reveal, Secret{Secret: util.HexEncode(make([]byte, secretSize))})
+
+	suite.ErrorIs(suite.bc.UpdateMempool(wrong), ErrInvalidTransaction)
+
+	seed := suite.bc.Seed(2 * defaultEpoch)
+	block := suite.forge(reveal)
+
+	// the secret is mixed into the seed of the next epoch
+	suite.Equal(suite.bc.Blocks[2].Beacon, suite.bc.Blocks[1].Beacon)
+	suite.NotEqual(suite.bc.Blocks[1].Beacon, block.Beacon)
+	suite.NotEqual(seed, suite.bc.Seed(2*defaultEpoch))
+	suite.Equal(util.HexEncode(secret), suite.bc.Beacon().Revealed[validator])
+
+	_, err = suite.bc.RevertBlock()
+	suite.NoError(err)
+	suite.Equal(seed, suite.bc.Seed(2*defaultEpoch))
+
+	// the validator withholds its secret, and is slashed once the epoch has passed
+	suite.Require().NoError(suite.bc.DropTransaction(reveal))
+	suite.Empty(suite.bc.Beacon().Revealed)
+
+	for suite.bc.Last().Slot < 2*defaultEpoch {
+		suite.forge(next())
+	}
+
+	suite.True(ToCoin(0.5).Equal(suite.bc.Validators(3 * defaultEpoch)[validator]))
+}
@@ -1,31 +1,50 @@
 package blockchain
 
 import (
+	"crypto/ecdsa"
 	"crypto/sha256"
 	"errors"
 	"fmt"
-	"time"
 
+	"backend/crypto"
 	"backend/util"
 )
 
-// errInvalidBlock is the base error when a block is invalid.
-var errInvalidBlock = errors.New("invalid block")
-
-// Block represents a singular block of the blockchain.
+// ErrInvalidBlock is the base error when a block is invalid.
+var ErrInvalidBlock = errors.New("invalid block")
+
+// Block represents a singular block of the blockchain. The header is signed by the
+// consensus key of the validator, such that the block can be verified regardless of
+// the peer that relayed it. The signature and the certificate, which holds the votes
+// that approved the block, are not part of its hash. The slot is the time
+// slot in which the block was proposed; see Clock. The rank is the position of the
+// validator within the proposers of the slot, where zero is the primary proposer.
+// The parent holds the certificate of the last block as seen by the validator, such
+// that the votes it carries are agreed upon; see trackLiveness. The beacon is the seed
+// that elected the proposers of the epoch of the block, which is recorded for audits.
+// The base fee is paid by every transaction in the block; see FeeMarket.
 type Block struct {
 	Validator    string        `json:"validator"`
 	MerkleRoot   string        `json:"merkleRoot"`
 	PrevHash     string        `json:"prevHash"`
 	Height       uint64        `json:"height"`
+	Slot         uint64        `json:"slot"`
+	Rank         uint32        `json:"rank"`
+	Beacon       string        `json:"beacon"`
+	BaseFee      float64       `json:"baseFee"`
 	Timestamp    int64         `json:"timestamp"`
 	Transactions []Transaction `json:"transactions"`
+	Evidence     []Evidence    `json:"evidence,omitempty"`
+	Parent       []Vote        `json:"parent,omitempty"`
+	Certificate  []Vote        `json:"certificate,omitempty"`
+	PublicKey    string        `json:"publicKey,omitempty"`
+	Signature    string        `json:"signature,omitempty"`
 }
 
 // newBlock creates a new Block.
-func newBlock(validator string, prevHash []byte, transactions []Transaction) (Block, error) {
+func newBlock(validator string, height uint64, slot uint64, timestamp int64, prevHash []byte, transactions []Transaction) (Block, error) {
 	if len(transactions) == 0 {
-		return Block{}, fmt.Errorf("%w: zero transactions", errInvalidBlock)
+		return Block{}, fmt.Errorf("%w: zero transactions", ErrInvalidBlock)
 	}
 
 	t, err := newMerkleTree(hashTransactions(transactions))
@@ -37,14 +56,18 @@ func newBlock(validator string, prevHash []byte, transactions []Transaction) (Bl
 		Validator:    validator,
 		MerkleRoot:   util.HexEncode(t.root.hash),
 		PrevHash:     util.HexEncode(prevHash),
-		Height:       uint64(len(transactions)),
-		Timestamp:    time.Now().Unix(),
+		Height:       height,
+		Slot:         slot,
+		Timestamp:    timestamp,
 		Transactions: transactions,
 	}, nil
 }
 
-// string returns the block as a string.
+// string returns the block as a string, excluding the signature and the certificate.
 func (b Block) string() string {
+	b.Certificate = nil
+	b.Signature = ""
+
 	return fmt.Sprintf("%v", b)
 }
 
@@ -56,38 +79,95 @@ func (b Block) Hash() []byte {
 	return h.Sum(nil)
 }
 
-// Validate validates a singular Block.
-func (b Block) Validate(last Block, validator string) error {
+// Sign signs the header of the Block with the consensus key of its validator.
+func (b *Block) Sign(priv *ecdsa.PrivateKey) error {
+	b.PublicKey = util.HexEncode(crypto.EncodePublicKey(&priv.PublicKey))
+
+	sig, err := crypto.Sign(priv, b.Hash())
+	if err != nil {
+		return err
+	}
+
+	b.Signature = util.HexEncode(sig)
+
+	return nil
+}
+
+// VerifySignature verifies whether the public key is the consensus key of the
+// validator, and whether the header has been signed by it.
+func (b Block) VerifySignature() error {
+	pub, err := consensusKey(b.Validator, b.PublicKey)
+	if err != nil {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "public key does not match validator")
+	}
+
+	if !crypto.Verify(pub, b.Hash(), util.HexDecode(b.Signature)) {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "invalid block signature")
+	}
+
+	return nil
+}
+
+// elected checks whether the validator of the Block was elected for its slot and rank
+// by the given seed and stakes. No block is accepted when no one could be elected;
+// anyone may forge a block when the election does not elect validators at all.
+func (b Block) elected(elect Election, seed []byte, stakes map[string]Coin) error {
+	if b.Rank >= Ranks {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "invalid rank")
+	}
+
+	proposers, err := elect(seed, b.Slot, stakes)
+	if err != nil {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "no validator could be elected")
+	}
+
+	if proposers == nil {
+		return nil
+	}
+
+	if uint32(len(proposers)) <= b.Rank || proposers[b.Rank] != b.Validator {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "validator was not elected")
+	}
+
+	return nil
+}
+
+// validate validates the structure of the Block and its linkage to the last Block.
+func (b Block) validate(last Block) error {
 	// compare hashes
 	if util.HexEncode(last.Hash()) != b.PrevHash {
-		return fmt.Errorf("%w, %s", errInvalidBlock, "hash does not match")
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "hash does not match")
 	}
 
 	// check timstamp
 	if last.Timestamp > b.Timestamp {
-		return fmt.Errorf("%w, %s", errInvalidBlock, "invalid timestamp")
-	}
-
-	// compare validator
-	if b.Validator != validator {
-		return fmt.Errorf("%w, %s", errInvalidBlock, "invalid validator")
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "invalid timestamp")
 	}
 
 	// create new tree
 	tr, err := newMerkleTree(hashTransactions(b.Transactions))
 	if err != nil {
-		return fmt.Errorf("%w, %s", errInvalidBlock, "failed to create tree")
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "failed to create tree")
 	}
 
 	// compare merkle root
 	if util.HexEncode(tr.root.hash) != b.MerkleRoot {
-		return fmt.Errorf("%w, %s", errInvalidBlock, "merkle root does not match")
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "merkle root does not match")
 	}
 
 	// compare height
-	if uint64(len(b.Transactions)) != b.Height {
-		return fmt.Errorf("%w, %s", errInvalidBlock, "height does not match")
+	if last.Height+1 != b.Height {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "height does not match")
 	}
 
-	return nil
+	// compare slot; slots without a block are skipped
+	if last.Slot >= b.Slot {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "slot does not follow")
+	}
+
+	if err = b.VerifySignature(); err != nil {
+		return err
+	}
+
+	return b.verifyCertificate()
 }
@@ -1,13 +1,16 @@
 package blockchain
 
 import (
+	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"math"
 	"os"
+	"sync"
 	"time"
 
 	"backend/crypto"
+	"backend/errors"
 	"backend/util"
 
 	"github.com/rs/zerolog/log"
@@ -16,77 +19,451 @@ import (
 // dumpFile the file name to whom the Blockchain should be written.
 const dumpFile string = "blockchain.json"
 
-// Blockchain holds all the blocks in the Blockchain.
+// revertLimit the amount of blocks that can be reverted.
+const revertLimit = 64
+
+// Blockchain holds all the blocks in the Blockchain, and the last finalized checkpoint.
 type Blockchain struct {
-	Blocks []Block `json:"blocks"`
-	mp     *mempool
-	am     *accountModel
+	Blocks     []Block    `json:"blocks"`
+	Finalized  Checkpoint `json:"finalized"`
+	justified  Checkpoint
+	mp         *mempool
+	am         *accountModel
+	ix         *indexer
+	bus        *Bus
+	journals   []*journal
+	reserved   map[string]*journal
+	eligible   Eligibility
+	elect      Election
+	certified  Certification
+	quorum     int
+	slot       time.Duration
+	epoch      uint64
+	punishment Punishment
+	downtime   Downtime
+	fees       FeeMarket
+	schedule   Schedule
+	limits     Limits
+	builder    Builder
+	evidence   map[string]Evidence
+	now        func() time.Time
+	mu         sync.Mutex
 }
 
 // NewBlockchain creates a new Blockchain.
 func NewBlockchain() *Blockchain {
 	return &Blockchain{
-		Blocks: make([]Block, 0),
-		am:     newAccountModel(),
-		mp:     newMempool(),
+		Blocks:    make([]Block, 0),
+		am:        newAccountModel(),
+		ix:        newIndexer(),
+		bus:       newBus(),
+		mp:        newMempool(),
+		journals:  make([]*journal, 0),
+		reserved:  make(map[string]*journal),
+		evidence:  make(map[string]Evidence),
+		eligible:  defaultEligibility,
+		elect:     defaultElection,
+		certified: defaultCertification,
+		quorum:    defaultQuorum,
+		limits:    defaultLimits,
+		builder:   defaultBuilder,
+		epoch:     defaultEpoch,
+		now:       time.Now,
+	}
+}
+
+// SetElection sets the election that determines which validator is allowed to forge
+// a block. The election is verified for every block, using the stakes derived from
+// the stake transactions of the blocks before the epoch of the block.
+func (b *Blockchain) SetElection(elect Election) {
+	b.elect = elect
+}
+
+// SetCertification sets the check that determines whether the certificate of a block
+// holds enough votes. The certificate is verified for every block, using the stakes
+// of the epoch of the block.
+func (b *Blockchain) SetCertification(certified Certification) {
+	b.certified = certified
+}
+
+// SetQuorum sets the percentage of the total weight of the validators that should
+// approve a block.
+func (b *Blockchain) SetQuorum(quorum int) error {
+	if 0 >= quorum || quorum > 100 {
+		return errors.ErrInvalidArgument("quorum should be a percentage")
 	}
+
+	b.quorum = quorum
+
+	return nil
+}
+
+// Last returns the last block of the blockchain.
+func (b *Blockchain) Last() Block {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.Blocks[len(b.Blocks)-1]
+}
+
+// Len returns the amount of blocks of the blockchain.
+func (b *Blockchain) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.Blocks)
+}
+
+// MarshalJSON encodes the blocks and the last finalized checkpoint, while no blocks
+// are being added.
+func (b *Blockchain) MarshalJSON() ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return json.Marshal(struct {
+		Blocks    []Block    `json:"blocks"`
+		Finalized Checkpoint `json:"finalized"`
+	}{b.Blocks, b.Finalized})
+}
+
+// Events returns the Bus on which the blockchain publishes its events.
+func (b *Blockchain) Events() *Bus {
+	return b.bus
+}
+
+// SetEligibility sets the check that is used to determine whether the validator
+// of a block was allowed to forge it.
+func (b *Blockchain) SetEligibility(eligible Eligibility) {
+	b.eligible = eligible
 }
 
 // Init initializes the blockchain and its account model.
-func (b *Blockchain) Init(validator string, blocks []Block) {
-	if len(blocks) > 0 {
-		b.Blocks = blocks
-	} else {
-		if err := b.createGenesis(validator); err != nil {
-			log.Fatal().Err(err).Msg("blockchain: failed to create genesis")
+// Every candidate chain is validated in order of fork choice. The first valid
+// candidate that includes the finalized checkpoint will be used; if there is none,
+// a new genesis block is created.
+func (b *Blockchain) Init(validator string, candidates ...[]Block) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// the journals and the reserved transactions belong to the previous account model;
+	// the memory pool is reserved again once the account model has been replaced
+	b.release()
+	b.journals = nil
+
+	defer b.reserve()
+
+	defer func() {
+		// the genesis block is always final
+		if !includes(b.Blocks, b.Finalized) || len(b.Finalized.Hash) == 0 {
+			b.Finalized = checkpoint(b.Blocks[0])
+		}
+
+		b.justified = b.Finalized
+	}()
+
+	sortCandidates(candidates)
+
+	for _, blocks := range candidates {
+		// finalized blocks can never be replaced
+		if !includes(blocks, b.Finalized) {
+			log.Warn().Int("blocks", len(blocks)).Msg("blockchain: rejected candidate chain without finalized checkpoint")
+
+			continue
 		}
+
+		am, err := validateBlocks(b.params(blocks[0]), blocks)
+		if err != nil {
+			log.Warn().Err(err).Int("blocks", len(blocks)).Msg("blockchain: rejected candidate chain")
+
+			continue
+		}
+
+		b.Blocks = blocks
+		b.am = am
+
+		b.ix.clear()
+		b.ix.add(b.Blocks...)
+
+		log.Debug().Int("blocks", len(blocks)).Msg("blockchain: initialized from candidate chain")
+
+		return
+	}
+
+	if err := b.createGenesis(validator); err != nil {
+		log.Fatal().Err(err).Msg("blockchain: failed to create genesis")
 	}
 
 	log.Debug().Msg("blockchain: initializing account model")
 
-	if len(b.Blocks) > 0 {
-		b.am.fromBlocks(b.Blocks...)
+	b.am = newAccountModel()
+	b.am.fromBlocks(b.Blocks...)
+
+	b.ix.clear()
+	b.ix.add(b.Blocks...)
+}
+
+// ValidateBlock validates a block, including all of its transactions, against the
+// last block of the blockchain. The account model is left untouched.
+func (b *Blockchain) ValidateBlock(block Block) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.release()
+	defer b.reserve()
+
+	// a proposed block is validated before it is voted on; its certificate is verified once it is added
+	p := b.params(b.Blocks[0])
+	p.certified = defaultCertification
+
+	j, err := validateNext(p, b.am, b.Blocks[len(b.Blocks)-1], block)
+	if err != nil {
+		return err
 	}
+
+	j.rollback()
+
+	return nil
 }
 
-// AddBlock adds a new block to the blockchain.
-func (b *Blockchain) AddBlock(block Block, validator string) {
-	if err := block.Validate(b.Blocks[len(b.Blocks)-1], validator); err != nil {
-		log.Error().Err(err).Msg("blockchain: block is invalid")
+// validateBlock validates a block, and applies its evidence and transactions to the
+// account model. The returned journal holds the changes made by the block. The reserved
+// transactions of the memory pool should be released beforehand.
+func (b *Blockchain) validateBlock(block Block) (*journal, error) {
+	return validateNext(b.params(b.Blocks[0]), b.am, b.Blocks[len(b.Blocks)-1], block)
+}
 
-		return
+// params returns the consensus parameters of the blockchain that starts at the given
+// genesis block.
+func (b *Blockchain) params(genesis Block) params {
+	return params{
+		eligible:   b.eligible,
+		elect:      b.elect,
+		certified:  b.certified,
+		quorum:     b.quorum,
+		clock:      b.clock(genesis),
+		punishment: b.punishment,
+		downtime:   b.downtime,
+		fees:       b.fees,
+		limits:     b.limits,
+		schedule:   b.schedule,
 	}
+}
 
-	for _, t := range block.Transactions {
-		if t.Type == Stake {
-			if err := b.UpdateAccountModel(t.Sender, t.Amount); err != nil {
-				log.Debug().Err(err).Msg("failed to update account")
-			}
-		} else {
-			if err := b.UpdateAccountModel(t.Receiver, t.Amount); err != nil {
-				log.Debug().Err(err).Msg("failed to update account")
-			}
-		}
+// AddBlock adds a new block to the blockchain. A block that competes with the last
+// block, as it follows the same parent, replaces the last block if it outranks it.
+func (b *Blockchain) AddBlock(block Block) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.release()
+	defer b.reserve()
+
+	if b.competes(block) {
+		return b.replace(block)
 	}
 
-	if err := b.mp.delete(block.Transactions...); err != nil {
+	return b.add(block)
+}
+
+// add validates the block and adds it to the blockchain. The reserved transactions
+// of the memory pool should be released beforehand.
+func (b *Blockchain) add(block Block) error {
+	j, err := b.validateBlock(block)
+	if err != nil {
+		return err
+	}
+
+	if err = b.mp.delete(block.Transactions...); err != nil {
 		log.Debug().Err(err).Msg("failed to remove transactions")
 	}
 
-	log.Info().Str("validator", validator).Msg("blockchain: added new block")
+	for _, e := range block.Evidence {
+		delete(b.evidence, e.key())
+	}
 
 	b.Blocks = append(b.Blocks, block)
+	b.journals = append(b.journals, j)
+	b.ix.add(block)
+
+	b.publishBlock(BlockAdded, block)
+
+	// blocks beyond the revert limit can no longer be reverted
+	if len(b.journals) > revertLimit {
+		b.journals[0].commit()
+		b.journals = b.journals[1:]
+	}
+
+	b.justify(block, j.certified)
+
+	log.Info().Str("validator", block.Validator).Msg("blockchain: added new block")
+
+	return nil
+}
+
+// RevertBlock reverts the last block of the blockchain. The account model will be
+// exactly as it was before the block was added, and its transactions are returned to
+// the memory pool if they are still valid.
+func (b *Blockchain) RevertBlock() (Block, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.release()
+	defer b.reserve()
+
+	return b.revert()
+}
+
+// revert reverts the last block of the blockchain. The reserved transactions of the
+// memory pool should be released beforehand.
+func (b *Blockchain) revert() (Block, error) {
+	if len(b.journals) == 0 {
+		return Block{}, errors.ErrInvalidOperation("block cannot be reverted")
+	}
+
+	block := b.Blocks[len(b.Blocks)-1]
+
+	if b.Finalized.Height >= block.Height {
+		return Block{}, errors.ErrInvalidOperation("finalized block cannot be reverted")
+	}
+
+	// the justification of a reverted checkpoint is lost
+	if b.justified.Height == block.Height {
+		b.justified = b.Finalized
+	}
+
+	b.journals[len(b.journals)-1].rollback()
+	b.journals = b.journals[:len(b.journals)-1]
+	b.Blocks = b.Blocks[:len(b.Blocks)-1]
+	b.ix.remove(block)
+
+	b.publishBlock(BlockReverted, block)
+
+	if err := b.mp.add(block.Transactions...); err != nil {
+		log.Debug().Err(err).Msg("blockchain: failed to return transactions to mempool")
+	}
+
+	for _, e := range block.Evidence {
+		b.evidence[e.key()] = e
+	}
+
+	log.Info().Uint64("height", block.Height).Msg("blockchain: reverted block")
+
+	return block, nil
 }
 
-// CreateBlock creates a new block.
-func (b *Blockchain) CreateBlock(validator string, amount uint32) (Block, error) {
-	transactions := b.mp.retrieve(amount)
+// release rolls back the changes of all transactions in the memory pool, such that
+// the account model only holds the changes made by blocks.
+func (b *Blockchain) release() {
+	for _, j := range b.reserved {
+		j.rollback()
+	}
+
+	b.reserved = make(map[string]*journal)
+}
+
+// reserve applies the transactions in the memory pool to the account model.
+// Transactions that are no longer valid will be removed from the memory pool.
+func (b *Blockchain) reserve() {
+	pending := b.mp.retrieve(0)
+
+	sortTransactions(pending)
+
+	for i, t := range pending {
+		j := b.pendingJournal()
+
+		if err := j.reserve(t); err != nil {
+			log.Debug().Err(err).Msg("blockchain: dropped transaction from mempool")
+
+			_ = b.mp.delete(t)
+
+			b.bus.publish(Event{Type: TxDropped, Transaction: &pending[i]})
+
+			continue
+		}
+
+		b.reserved[t.String()] = j
+	}
+}
+
+// pendingJournal creates a journal for a transaction in the memory pool, which is
+// applied under the rules, and with the base fee, of the next block.
+func (b *Blockchain) pendingJournal() *journal {
+	last := b.Blocks[len(b.Blocks)-1]
+
+	p := b.governed()
+
+	return newJournal(b.am, last.Height+1).charging(p.baseFee(last), p.fees.GasPrice, "").under(b.schedule.Rules(last.Height + 1))
+}
 
-	block, err := newBlock(validator, b.Blocks[len(b.Blocks)-1].Hash(), transactions)
+// CreateBlock creates a new block, proposed by the validator of the given rank, and
+// signs it with the consensus key of the validator. Only evidence and transactions
+// that are valid against the current state will be added; the builder selects the
+// transactions from the memory pool.
+func (b *Blockchain) CreateBlock(key *ecdsa.PrivateKey, rank uint32) (Block, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.release()
+	defer b.reserve()
+
+	last := b.Blocks[len(b.Blocks)-1]
+	slot := b.nextSlot(last)
+	pending := b.mp.retrieve(0)
+	evidence := make([]Evidence, 0)
+	epoch := b.clock(b.Blocks[0]).EpochOf(slot)
+	rules := b.schedule.Rules(last.Height + 1)
+	j := newJournal(b.am, last.Height+1).under(rules)
+
+	// the proposals that passed change the parameters from this block onwards
+	if rules.Active(GovernanceFork) {
+		j.govern(b.am.validatorsAt(epoch))
+	}
+
+	p := b.governed()
+	baseFee := p.baseFee(last)
+
+	// transactions are applied within the epoch of the block
+	j.charging(baseFee, p.fees.GasPrice, crypto.Address(&key.PublicKey)).unbondingFor(p.punishment.Unbonding)
+	j.advance(epoch, p.punishment.Withhold)
+
+	// proposers are elected by slot alone before the beacon fork
+	var seed string
+
+	if rules.Active(BeaconFork) {
+		seed = b.am.getBeacon().Seed
+	}
+
+	// evidence is applied before the transactions, as it might slash stake
+	for _, e := range b.pendingEvidence() {
+		if err := j.punish(e, p.punishment); err != nil {
+			continue
+		}
+
+		evidence = append(evidence, e)
+	}
+
+	transactions := b.builder.within(p.limits).build(j, pending)
+
+	j.rollback()
+
+	block, err := newBlock(crypto.Address(&key.PublicKey), last.Height+1, slot, b.now().Unix(), last.Hash(), transactions)
 	if err != nil {
 		return Block{}, err
 	}
 
+	block.Rank = rank
+	block.Beacon = seed
+	block.BaseFee = baseFee.Float64()
+	block.Parent = last.Certificate
+
+	if len(evidence) > 0 {
+		block.Evidence = evidence
+	}
+
+	if err = block.Sign(key); err != nil {
+		return Block{}, err
+	}
+
 	return block, nil
 }
 
@@ -103,16 +480,17 @@ func (b *Blockchain) createGenesis(validator string) error {
 	}
 
 	t := Transaction{
-		Sender:    util.HexEncode(crypto.EncodePublicKey(pub)),
-		Receiver:  util.HexEncode(crypto.EncodePublicKey(pub)),
+		Sender:    crypto.Address(pub),
+		PublicKey: util.HexEncode(crypto.EncodePublicKey(pub)),
+		Receiver:  crypto.Address(pub),
 		Signature: util.HexEncode(sign),
 		Amount:    ToCoin(math.MaxUint64).Float64(),
 		Nonce:     0,
-		Timestamp: time.Now().Unix(),
+		Timestamp: b.now().Unix(),
 		Type:      Exchange,
 	}
 
-	block, err := newBlock(validator, []byte(""), []Transaction{t})
+	block, err := newBlock(validator, 0, 0, t.Timestamp, []byte(""), []Transaction{t})
 	if err != nil {
 		return err
 	}
@@ -124,7 +502,8 @@ func (b *Blockchain) createGenesis(validator string) error {
 	return nil
 }
 
-// FromFile returns all blocks that are written to the dumpfile.
+// FromFile returns all blocks that are written to the dumpfile, and restores the
+// finalized checkpoint that was written along with them.
 func (b *Blockchain) FromFile() ([]Block, error) {
 	var blockchain Blockchain
 
@@ -141,46 +520,190 @@ func (b *Blockchain) FromFile() ([]Block, error) {
 
 	log.Debug().Msg("blockchain: reading from file")
 
+	// the finalized checkpoint of this node should be kept when synchronizing
+	b.mu.Lock()
+	b.Finalized = blockchain.Finalized
+	b.mu.Unlock()
+
 	return blockchain.Blocks, nil
 }
 
-// UpdateMempool tries to update or add to the memory pool.
+// UpdateMempool tries to add a transaction to the memory pool.
+// The sender is debited until the transaction has been forged into a block,
+// or until the transaction is dropped.
 func (b *Blockchain) UpdateMempool(transaction Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	if b.mp.exists(transaction.String()) {
 		return fmt.Errorf("%w: duplicate transaction", ErrInvalidTransaction)
 	}
 
+	j := b.pendingJournal()
+
+	if err := j.reserve(transaction); err != nil {
+		return err
+	}
+
 	if err := b.mp.add(transaction); err != nil {
+		j.rollback()
+
 		return err
 	}
 
+	b.reserved[transaction.String()] = j
+
+	b.bus.publish(Event{Type: TxAccepted, Transaction: &transaction})
+
 	return nil
 }
 
-// UpdateAccountModel tries to update or add to the account model.
-func (b *Blockchain) UpdateAccountModel(key string, amount float64) error {
-	if b.am.exists(key) {
-		if err := b.am.update(key, amount); err != nil {
-			return err
-		}
-	} else {
-		if err := b.am.add(key, amount); err != nil {
-			return err
-		}
+// DropTransaction removes a transaction from the memory pool, and rolls back
+// the changes it made to the account model. The remaining transactions are
+// reserved again, such that those that depend on the dropped one are dropped too.
+func (b *Blockchain) DropTransaction(transaction Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.mp.exists(transaction.String()) {
+		return b.mp.delete(transaction)
 	}
 
+	b.release()
+	_ = b.mp.delete(transaction)
+	b.reserve()
+
+	b.bus.publish(Event{Type: TxDropped, Transaction: &transaction})
+
 	return nil
 }
 
-// GetAccount returns the account associated with the given key.
+// publishBlock publishes an event for the given block, and a StakeChanged event
+// for every stake and unstake within the block.
+func (b *Blockchain) publishBlock(eventType EventType, block Block) {
+	b.bus.publish(Event{Type: eventType, Height: block.Height, Block: &block})
+
+	for i := range block.Transactions {
+		if t := block.Transactions[i].Type; t == Stake || t == Unstake {
+			b.bus.publish(Event{Type: StakeChanged, Height: block.Height, Transaction: &block.Transactions[i]})
+		}
+	}
+}
+
+// Validators returns the stake of every validator in the epoch of the given slot, as
+// derived from the stake transactions within the blocks before that epoch.
+func (b *Blockchain) Validators(slot uint64) map[string]Coin {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.am.validatorsAt(b.clock(b.genesis()).EpochOf(slot))
+}
+
+// Supply returns the funds held by accounts, bonded as stake or held in escrow, and the
+// funds that have been burned; together they amount to the funds created at genesis.
+func (b *Blockchain) Supply() (Coin, Coin) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// the senders of pending transactions have been debited
+	b.release()
+	defer b.reserve()
+
+	return b.am.supply()
+}
+
+// GetAccount returns a copy of the account associated with the given key.
 func (b *Blockchain) GetAccount(key string) (*Account, error) {
-	return b.am.get(key)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	a, err := b.am.get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	c := *a
+
+	return &c, nil
+}
+
+// GetEscrow returns the Escrow associated with the given id.
+func (b *Blockchain) GetEscrow(id string) (Escrow, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.am.getEscrow(id)
+}
+
+// GetContract returns the SmartContract deployed at the given address.
+func (b *Blockchain) GetContract(address string) (SmartContract, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.am.getContract(address)
+}
+
+// GetReceipt returns the Receipt of the Deploy or Call transaction with the given hash.
+func (b *Blockchain) GetReceipt(hash string) (Receipt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.am.getReceipt(hash)
+}
+
+// GetName returns the registration of the given name, which may have expired.
+func (b *Blockchain) GetName(name string) (Name, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.am.getName(name)
+}
+
+// Resolve returns the address the given name resolves to; a name resolves to its
+// owner as long as the registration has not expired for the next block.
+func (b *Blockchain) Resolve(name string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n, err := b.am.getName(name)
+	if err != nil {
+		return "", err
+	}
+
+	if !n.active(uint64(len(b.Blocks))) {
+		return "", errors.ErrInvalidOperation("name has expired")
+	}
+
+	return n.Owner, nil
+}
+
+// History returns a page of the transactions sent and received by the given address,
+// starting with the most recent transaction. The total amount of transactions of the
+// address is returned as well.
+func (b *Blockchain) History(address string, page int, limit int) ([]Record, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	refs, total := b.ix.get(address, page, limit)
+	records := make([]Record, 0, len(refs))
+
+	for _, ref := range refs {
+		records = append(records, Record{
+			Transaction: b.Blocks[ref.height].Transactions[ref.position],
+			Direction:   ref.direction,
+			Height:      ref.height,
+			Position:    ref.position,
+		})
+	}
+
+	return records, total
 }
 
 // DumpJSON writes the current Blockchain to a JSON file.
 func (b *Blockchain) DumpJSON() error {
 	log.Debug().Msg("blockchain: writing to file")
 
+	// the blocks are encoded while holding the lock; see MarshalJSON
 	data, err := json.MarshalIndent(b, "", " ")
 	if err != nil {
 		return err
@@ -0,0 +1,220 @@
+package blockchain
+
+import (
+	"container/heap"
+	"fmt"
+	"strings"
+
+	"backend/errors"
+	"backend/util"
+)
+
+// Candidate is the next transaction of a sender that can be added to a block; added is
+// the amount of transactions of the sender that have been added to the block before it.
+type Candidate struct {
+	Transaction Transaction
+	Added       int
+}
+
+// Strategy decides whether the first candidate should be added to a block before the
+// second. Only the next transaction of every sender is a candidate, such that the
+// transactions of a sender are always added in the order of their nonce.
+type Strategy func(a Candidate, b Candidate) bool
+
+// earlier orders transactions by their timestamp, and by their hash when equal.
+func earlier(a Transaction, b Transaction) bool {
+	if a.Timestamp != b.Timestamp {
+		return a.Timestamp < b.Timestamp
+	}
+
+	return util.HexEncode(a.Hash()) < util.HexEncode(b.Hash())
+}
+
+// HighestFee adds the transactions that offer the highest tip first.
+func HighestFee(a Candidate, b Candidate) bool {
+	if fa, fb := a.Transaction.fee(), b.Transaction.fee(); !fa.Equal(fb) {
+		return fa.Float64() > fb.Float64()
+	}
+
+	return earlier(a.Transaction, b.Transaction)
+}
+
+// FIFO adds the transactions in the order they were created.
+func FIFO(a Candidate, b Candidate) bool {
+	return earlier(a.Transaction, b.Transaction)
+}
+
+// Fair adds one transaction of every sender in turn, such that no sender can fill a
+// block on its own; the transactions of a turn are added in the order they were created.
+func Fair(a Candidate, b Candidate) bool {
+	if a.Added != b.Added {
+		return a.Added < b.Added
+	}
+
+	return earlier(a.Transaction, b.Transaction)
+}
+
+// ParseStrategy returns the Strategy with the given name; either "fee", "fifo" or "fair".
+func ParseStrategy(name string) (Strategy, error) {
+	switch strings.ToLower(name) {
+	case "fee":
+		return HighestFee, nil
+	case "fifo":
+		return FIFO, nil
+	case "fair":
+		return Fair, nil
+	default:
+		return nil, errors.ErrInvalidArgument("unknown strategy %s", name)
+	}
+}
+
+// Builder selects the transactions of a new block from the memory pool, in the order of
+// its strategy. The transactions should fit within the budget of bytes and gas of a block;
+// the gas of a transaction is the gas it may use at most.
+type Builder struct {
+	Strategy Strategy
+	MaxBytes int
+	MaxGas   uint64
+}
+
+// Limits holds the maximum size, in bytes, and the maximum gas of the transactions of a
+// block, which every block should respect; zero means there is no limit. The limits are
+// changed through governance.
+type Limits struct {
+	MaxBytes int    `json:"maxBytes"`
+	MaxGas   uint64 `json:"maxGas"`
+}
+
+// check checks whether the transactions fit within the limits.
+func (l Limits) check(transactions []Transaction) error {
+	size, gas := 0, uint64(0)
+
+	for _, t := range transactions {
+		size, gas = size+t.size(), gas+t.gas()
+	}
+
+	if l.MaxBytes > 0 && size > l.MaxBytes {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "transactions exceed the size limit")
+	}
+
+	if l.MaxGas > 0 && gas > l.MaxGas {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "transactions exceed the gas limit")
+	}
+
+	return nil
+}
+
+// within returns the builder with its budget lowered to the limits.
+func (bd Builder) within(l Limits) Builder {
+	if l.MaxBytes > 0 && l.MaxBytes < bd.MaxBytes {
+		bd.MaxBytes = l.MaxBytes
+	}
+
+	if l.MaxGas > 0 && l.MaxGas < bd.MaxGas {
+		bd.MaxGas = l.MaxGas
+	}
+
+	return bd
+}
+
+// defaultLimits limits the transactions of a block to 512 KiB and ten times the gas a
+// single transaction may use.
+var defaultLimits = Limits{MaxBytes: 512 << 10, MaxGas: 10 * maxGas}
+
+// defaultBuilder adds the transactions that offer the highest tip first, within the
+// default limits.
+var defaultBuilder = Builder{Strategy: HighestFee, MaxBytes: defaultLimits.MaxBytes, MaxGas: defaultLimits.MaxGas}
+
+// size returns the size of the transaction, in bytes, as it is sent to other nodes.
+func (t Transaction) size() int {
+	return len(util.JSONEncode(t))
+}
+
+// gas returns the gas the transaction may use at most.
+func (t Transaction) gas() uint64 {
+	switch t.Type {
+	case Deploy, Call:
+		if i, err := t.invocation(); err == nil {
+			return i.Gas
+		}
+	case Stake, Unstake, Regular, Reward, Fee, Penalty, Exchange, Lock, Claim, Refund, Register, Renew, Transfer, Unjail, Commitment, Reveal, Propose, Ballot:
+	}
+
+	return 0
+}
+
+// candidates is a heap of candidates, ordered by a strategy.
+type candidates struct {
+	items []Candidate
+	less  Strategy
+}
+
+func (c *candidates) Len() int           { return len(c.items) }
+func (c *candidates) Less(i, j int) bool { return c.less(c.items[i], c.items[j]) }
+func (c *candidates) Swap(i, j int)      { c.items[i], c.items[j] = c.items[j], c.items[i] }
+func (c *candidates) Push(x any)         { c.items = append(c.items, x.(Candidate)) }
+
+func (c *candidates) Pop() any {
+	n := len(c.items)
+	item := c.items[n-1]
+	c.items = c.items[:n-1]
+
+	return item
+}
+
+// build applies the pending transactions through the journal in the order of the
+// strategy, and returns the transactions that are valid and fit within the budget.
+func (bd Builder) build(j *journal, pending []Transaction) []Transaction {
+	sortTransactions(pending)
+
+	// the transactions of every sender, ordered by nonce
+	queues := make(map[string][]Transaction)
+
+	for _, t := range pending {
+		queues[t.Sender] = append(queues[t.Sender], t)
+	}
+
+	h := &candidates{less: bd.Strategy}
+
+	for sender, queue := range queues {
+		h.items = append(h.items, Candidate{Transaction: queue[0]})
+		queues[sender] = queue[1:]
+	}
+
+	heap.Init(h)
+
+	transactions := make([]Transaction, 0)
+	size, gas := 0, uint64(0)
+
+	for h.Len() > 0 {
+		c := heap.Pop(h).(Candidate)
+		t := c.Transaction
+
+		added := size+t.size() <= bd.MaxBytes && gas+t.gas() <= bd.MaxGas && j.apply(t) == nil
+		if added {
+			transactions = append(transactions, t)
+			size, gas = size+t.size(), gas+t.gas()
+			c.Added++
+		}
+
+		// the next transaction of the sender becomes a candidate, even when this one was
+		// not added; it might replace it with the same nonce
+		if queue := queues[t.Sender]; len(queue) > 0 {
+			heap.Push(h, Candidate{Transaction: queue[0], Added: c.Added})
+			queues[t.Sender] = queue[1:]
+		}
+	}
+
+	return transactions
+}
+
+// SetLimits sets the limits that the transactions of every block should respect.
+func (b *Blockchain) SetLimits(limits Limits) {
+	b.limits = limits
+}
+
+// SetBuilder sets the builder that selects the transactions of the blocks created by
+// this node.
+func (b *Blockchain) SetBuilder(builder Builder) {
+	b.builder = builder
+}
@@ -0,0 +1,65 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrategies(t *testing.T) {
+	cheap := Candidate{Transaction: Transaction{Sender: "a", Tip: 1, Timestamp: 1}}
+	expensive := Candidate{Transaction: Transaction{Sender: "b", Tip: 100, Timestamp: 2}, Added: 1}
+
+	assert.True(t, HighestFee(expensive, cheap))
+	assert.True(t, FIFO(cheap, expensive))
+
+	// a sender that has transactions in the block waits for its turn
+	assert.True(t, Fair(cheap, expensive))
+
+	expensive.Added = 0
+	assert.True(t, Fair(cheap, expensive))
+	assert.False(t, Fair(expensive, cheap))
+
+	for _, name := range []string{"fee", "FIFO", "fair"} {
+		_, err := ParseStrategy(name)
+		assert.NoError(t, err)
+	}
+
+	_, err := ParseStrategy("random")
+	assert.Error(t, err)
+}
+
+func (suite *ValidationTestSuite) TestBuilder() {
+	first, second := suite.transaction(1, 1), suite.transaction(100, 2)
+
+	// the second transaction offers a higher fee, but depends on the first
+	block := suite.forge(first, second)
+
+	suite.Equal([]Transaction{first, second}, block.Transactions)
+
+	// only transactions that fit within the budget are added
+	suite.bc.SetBuilder(Builder{Strategy: FIFO, MaxBytes: first.size(), MaxGas: maxGas})
+
+	block = suite.forge(suite.transaction(1, 3), suite.transaction(1, 4))
+	suite.Len(block.Transactions, 1)
+
+	block = suite.forge()
+	suite.Len(block.Transactions, 1)
+	suite.Equal(uint64(4), block.Transactions[0].Nonce)
+}
+
+func (suite *ValidationTestSuite) TestLimits() {
+	suite.Equal(defaultLimits, suite.params().limits)
+
+	first, second := suite.transaction(1, 1), suite.transaction(1, 2)
+
+	suite.bc.SetLimits(Limits{MaxBytes: first.size() + second.size() - 1, MaxGas: maxGas})
+
+	// a block that exceeds the limits is invalid, whoever created it
+	invalid := suite.block(suite.key, suite.bc.Last(), suite.bc.Last().Slot+1, first, second)
+	suite.ErrorIs(suite.bc.AddBlock(invalid), ErrInvalidBlock)
+
+	// the builder of this node respects the limits
+	block := suite.forge(first, second)
+	suite.Len(block.Transactions, 1)
+}
@@ -0,0 +1,141 @@
+package blockchain
+
+import (
+	"fmt"
+	"time"
+
+	"backend/errors"
+)
+
+// defaultEpoch is the default amount of slots within an epoch.
+const defaultEpoch uint64 = 32
+
+// Ranks is the amount of proposers of a slot; the primary proposer is followed by
+// backup proposers, which may only propose once the earlier ranks missed their window.
+const Ranks uint32 = 3
+
+// Clock divides the time since the genesis block into slots of a fixed duration, and
+// the slots into epochs. Every slot is divided into a window for every rank of its
+// proposers, and changes to the validator set take effect at the first slot of the
+// next epoch. A clock without a duration has no timing; every block then simply
+// occupies the slot following its predecessor.
+type Clock struct {
+	Genesis  time.Time
+	Duration time.Duration
+	Epoch    uint64
+}
+
+// timed checks whether the slots of the clock have a duration.
+func (c Clock) timed() bool {
+	return c.Duration > 0
+}
+
+// Slot returns the slot at the given time.
+func (c Clock) Slot(t time.Time) uint64 {
+	if !c.timed() || t.Before(c.Genesis) {
+		return 0
+	}
+
+	return uint64(t.Sub(c.Genesis) / c.Duration)
+}
+
+// Start returns the time at which the slot starts.
+func (c Clock) Start(slot uint64) time.Time {
+	return c.Genesis.Add(time.Duration(slot) * c.Duration)
+}
+
+// Timeout returns the duration of the window of a single rank.
+func (c Clock) Timeout() time.Duration {
+	return c.Duration / time.Duration(Ranks)
+}
+
+// Window returns the time at which the proposer of the given rank may propose a
+// block in the slot.
+func (c Clock) Window(slot uint64, rank uint32) time.Time {
+	return c.Start(slot).Add(time.Duration(rank) * c.Timeout())
+}
+
+// EpochOf returns the epoch the slot belongs to.
+func (c Clock) EpochOf(slot uint64) uint64 {
+	return slot / c.Epoch
+}
+
+// verify checks whether the block has been created within its slot, and not before
+// the window of its rank.
+func (c Clock) verify(block Block) error {
+	if !c.timed() {
+		return nil
+	}
+
+	if c.Slot(time.Unix(block.Timestamp, 0)) != block.Slot {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "timestamp is not within slot")
+	}
+
+	// timestamps are in seconds
+	if c.Window(block.Slot, block.Rank).Unix() > block.Timestamp {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "timestamp is before window of rank")
+	}
+
+	return nil
+}
+
+// SetClock sets the duration of a slot, and the amount of slots within an epoch.
+// The clock starts at the timestamp of the genesis block.
+func (b *Blockchain) SetClock(duration time.Duration, epoch uint64) error {
+	if 0 >= duration {
+		return errors.ErrInvalidArgument("slot duration should be positive")
+	}
+
+	if epoch == 0 {
+		return errors.ErrInvalidArgument("epoch should hold at least one slot")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.slot = duration
+	b.epoch = epoch
+
+	return nil
+}
+
+// SetTime sets the source of the current time, which determines the timestamps and
+// slots of new blocks; the wall clock is used by default.
+func (b *Blockchain) SetTime(now func() time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.now = now
+}
+
+// Clock returns the slot clock of the blockchain.
+func (b *Blockchain) Clock() Clock {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.clock(b.genesis())
+}
+
+// genesis returns the genesis block, or an empty block if the blockchain has not
+// been initialized yet.
+func (b *Blockchain) genesis() Block {
+	if len(b.Blocks) == 0 {
+		return Block{}
+	}
+
+	return b.Blocks[0]
+}
+
+// clock returns the slot clock that starts at the given genesis block.
+func (b *Blockchain) clock(genesis Block) Clock {
+	return Clock{Genesis: time.Unix(genesis.Timestamp, 0), Duration: b.slot, Epoch: b.epoch}
+}
+
+// nextSlot returns the slot of a block that is created now, following the last block.
+func (b *Blockchain) nextSlot(last Block) uint64 {
+	if c := b.clock(b.Blocks[0]); c.timed() {
+		return c.Slot(b.now())
+	}
+
+	return last.Slot + 1
+}
@@ -0,0 +1,54 @@
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	genesis := time.Unix(1000, 0)
+	c := Clock{Genesis: genesis, Duration: 10 * time.Second, Epoch: 4}
+
+	assert.Equal(t, uint64(0), c.Slot(genesis.Add(-time.Second)))
+	assert.Equal(t, uint64(0), c.Slot(genesis.Add(9*time.Second)))
+	assert.Equal(t, uint64(5), c.Slot(genesis.Add(55*time.Second)))
+	assert.Equal(t, genesis.Add(50*time.Second), c.Start(5))
+	assert.Equal(t, uint64(1), c.EpochOf(5))
+
+	assert.NoError(t, c.verify(Block{Slot: 5, Timestamp: 1050}))
+	assert.ErrorIs(t, c.verify(Block{Slot: 5, Timestamp: 1060}), ErrInvalidBlock)
+
+	// without a duration there is no timing
+	assert.NoError(t, Clock{Epoch: 4}.verify(Block{Slot: 5, Timestamp: 1060}))
+}
+
+func TestSetClock(t *testing.T) {
+	bc := NewBlockchain()
+
+	assert.Error(t, bc.SetClock(0, 4))
+	assert.Error(t, bc.SetClock(time.Second, 0))
+	assert.NoError(t, bc.SetClock(time.Second, 4))
+
+	bc.Init("validator")
+
+	c := bc.Clock()
+
+	assert.Equal(t, time.Unix(bc.Blocks[0].Timestamp, 0), c.Genesis)
+	assert.Equal(t, time.Second, c.Duration)
+	assert.Equal(t, uint64(4), c.Epoch)
+}
+
+func TestClockWindow(t *testing.T) {
+	genesis := time.Unix(1000, 0)
+	c := Clock{Genesis: genesis, Duration: 30 * time.Second, Epoch: 4}
+
+	assert.Equal(t, 10*time.Second, c.Timeout())
+	assert.Equal(t, genesis.Add(50*time.Second), c.Window(1, 2))
+
+	// a backup may not propose before the window of its rank
+	assert.ErrorIs(t, c.verify(Block{Slot: 1, Rank: 2, Timestamp: 1045}), ErrInvalidBlock)
+	assert.NoError(t, c.verify(Block{Slot: 1, Rank: 2, Timestamp: 1050}))
+	assert.NoError(t, c.verify(Block{Slot: 1, Rank: 0, Timestamp: 1045}))
+}
@@ -1,6 +1,10 @@
 package blockchain
 
-import "github.com/shopspring/decimal"
+import (
+	"math/big"
+
+	"github.com/shopspring/decimal"
+)
 
 // Coin represents the currency within the blockchain.
 type Coin struct {
@@ -29,6 +33,11 @@ func (c Coin) Float64() float64 {
 	return f
 }
 
+// Cents returns the Coin as a whole number of cents, which is exact for any amount.
+func (c Coin) Cents() *big.Int {
+	return c.decimal.Shift(2).Round(0).BigInt()
+}
+
 // Equal checks if two coins are equal.
 func (c Coin) Equal(coin Coin) bool {
 	return c.decimal.Equal(coin.decimal)
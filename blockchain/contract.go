@@ -0,0 +1,190 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"backend/crypto"
+	"backend/util"
+	"backend/vm"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxGas the maximum amount of gas a singular transaction can use.
+const maxGas = 1000000
+
+// SmartContract represents a contract that has been deployed on the blockchain.
+// The address of the contract is derived from the hash of the transaction that deployed it.
+type SmartContract struct {
+	Creator string          `json:"creator"`
+	Code    string          `json:"code"`
+	Storage map[int64]int64 `json:"storage"`
+}
+
+// Invocation holds the parameters of a Deploy or Call transaction.
+// A Deploy sets the code; a Call sets the input. The amount of the transaction
+// is transferred to the contract.
+type Invocation struct {
+	Code  string  `json:"code,omitempty"`
+	Input []int64 `json:"input,omitempty"`
+	Gas   uint64  `json:"gas"`
+}
+
+// Receipt holds the result of a Deploy or Call transaction. A failed execution is still
+// part of the block; all of its changes are discarded, except for the nonce of the sender
+// and the cost of the gas it used.
+type Receipt struct {
+	Contract string  `json:"contract"`
+	Height   uint64  `json:"height"`
+	Success  bool    `json:"success"`
+	Return   int64   `json:"return"`
+	GasUsed  uint64  `json:"gasUsed"`
+	Cost     float64 `json:"cost"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// storage provides journaled access to the storage of a SmartContract.
+type storage struct {
+	j       *journal
+	address string
+}
+
+// Get reads a value from the storage.
+func (s storage) Get(key int64) int64 {
+	return s.j.am.load(s.address, key)
+}
+
+// Set writes a value to the storage.
+func (s storage) Set(key int64, value int64) {
+	s.j.store(s.address, key, value)
+}
+
+// invocation decodes the Invocation of the transaction.
+func (t Transaction) invocation() (Invocation, error) {
+	var i Invocation
+
+	if err := json.Unmarshal([]byte(t.Data), &i); err != nil {
+		return Invocation{}, fmt.Errorf("%w: invalid invocation", ErrInvalidTransaction)
+	}
+
+	return i, nil
+}
+
+// invocable checks whether the Deploy or Call transaction is valid against the current state.
+func (j *journal) invocable(transaction Transaction) (Invocation, error) {
+	i, err := transaction.invocation()
+	if err != nil {
+		return Invocation{}, err
+	}
+
+	if i.Gas > maxGas {
+		return Invocation{}, fmt.Errorf("%w: gas exceeds %d", ErrInvalidTransaction, maxGas)
+	}
+
+	if transaction.Type == Deploy {
+		if err = vm.Validate(util.HexDecode(i.Code)); err != nil {
+			return Invocation{}, fmt.Errorf("%w: %s", ErrInvalidTransaction, err.Error())
+		}
+
+		return i, nil
+	}
+
+	if _, err = j.am.getContract(transaction.Receiver); err != nil {
+		return Invocation{}, fmt.Errorf("%w: unknown contract", ErrInvalidTransaction)
+	}
+
+	return i, nil
+}
+
+// gasCost returns the cost of the gas at the gas price, rounded up to cents; gas is
+// not charged before the fees fork.
+func (j *journal) gasCost(gas uint64) float64 {
+	if !j.rules.Active(FeesFork) {
+		return 0
+	}
+
+	cost := decimal.NewFromFloat(j.gasPrice).Mul(decimal.NewFromInt(int64(gas))).Mul(decimal.NewFromInt(100)).Ceil()
+
+	return cost.Div(decimal.NewFromInt(100)).InexactFloat64()
+}
+
+// invoke applies a Deploy or Call transaction, and adds its Receipt. The sender of a
+// Call pays for all of its gas upfront, and is refunded for the gas that is not used;
+// the cost of the gas used is burned.
+func (j *journal) invoke(transaction Transaction) error {
+	i, err := j.invocable(transaction)
+	if err != nil {
+		return err
+	}
+
+	hash := util.HexEncode(transaction.Hash())
+	receipt := Receipt{Height: j.height, Success: true}
+
+	if transaction.Type == Deploy {
+		if err = j.debit(transaction.Sender, transaction.Amount, true); err != nil {
+			return err
+		}
+
+		address := crypto.NewAddress(transaction.Hash())
+		receipt.Contract = address
+
+		j.deploy(address, &SmartContract{
+			Creator: transaction.Sender,
+			Code:    i.Code,
+			Storage: make(map[int64]int64),
+		})
+		j.credit(address, transaction.Amount)
+		j.receipt(hash, receipt)
+
+		return nil
+	}
+
+	limit := j.gasCost(i.Gas)
+
+	if err = j.debit(transaction.Sender, ToCoin(transaction.Amount).Add(limit).Float64(), true); err != nil {
+		return err
+	}
+
+	receipt.Contract = transaction.Receiver
+
+	contract, _ := j.am.getContract(transaction.Receiver)
+	mark := len(j.changes)
+
+	j.credit(transaction.Receiver, transaction.Amount)
+
+	res, err := vm.Execute(util.HexDecode(contract.Code), vm.Context{
+		Input:  i.Input,
+		Value:  int64(math.Round(transaction.Amount * 100)),
+		Height: j.height,
+		Gas:    i.Gas,
+	}, storage{j: j, address: transaction.Receiver})
+
+	receipt.Return = res.Return
+	receipt.GasUsed = res.GasUsed
+
+	if err != nil {
+		// discard the transfer and every change to the storage
+		j.rollbackTo(mark)
+		j.credit(transaction.Sender, transaction.Amount)
+
+		receipt.Success = false
+		receipt.Return = 0
+		receipt.Error = err.Error()
+	}
+
+	receipt.Cost = j.gasCost(res.GasUsed)
+
+	if refund := ToCoin(limit).Sub(receipt.Cost); !refund.Equal(ToCoin(0)) {
+		j.credit(transaction.Sender, refund.Float64())
+	}
+
+	if receipt.Cost > 0 {
+		j.burn(receipt.Cost)
+	}
+
+	j.receipt(hash, receipt)
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package blockchain
+
+import (
+	"testing"
+
+	"backend/crypto"
+	"backend/util"
+	"backend/vm"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// counter adds the first input to the value stored at key 0; it reverts when the input is zero.
+const counter = `
+PUSH 0
+INPUT
+ISZERO
+PUSH 63
+JUMPI
+PUSH 0
+PUSH 0
+LOAD
+PUSH 0
+INPUT
+ADD
+STORE
+PUSH 0
+LOAD
+RETURN
+REVERT
+`
+
+type ContractTestSuite struct {
+	suite.Suite
+	am      *accountModel
+	address string
+	hash    string
+}
+
+func (suite *ContractTestSuite) SetupTest() {
+	code, err := vm.Assemble(counter)
+	suite.Require().NoError(err)
+
+	suite.am = newAccountModel()
+
+	_ = suite.am.add("alice", 100)
+
+	deploy := Transaction{
+		Sender: "alice",
+		Type:   Deploy,
+		Data:   string(util.JSONEncode(Invocation{Code: util.HexEncode(code)})),
+	}
+	suite.address = crypto.NewAddress(deploy.Hash())
+	suite.hash = util.HexEncode(deploy.Hash())
+
+	suite.Require().NoError(newJournal(suite.am, 1).apply(deploy))
+}
+
+func TestContractTestSuite(t *testing.T) {
+	suite.Run(t, new(ContractTestSuite))
+}
+
+// call creates a Call transaction to the contract.
+func (suite *ContractTestSuite) call(amount float64, input ...int64) Transaction {
+	return Transaction{
+		Sender:   "alice",
+		Receiver: suite.address,
+		Amount:   amount,
+		Nonce:    suite.am.accounts["alice"].Transactions,
+		Type:     Call,
+		Data:     string(util.JSONEncode(Invocation{Input: input, Gas: 1000})),
+	}
+}
+
+func (suite *ContractTestSuite) TestDeploy() {
+	receipt, err := suite.am.getReceipt(suite.hash)
+
+	suite.NoError(err)
+	suite.True(receipt.Success)
+	suite.Equal(suite.address, receipt.Contract)
+
+	_, err = suite.am.getContract(suite.address)
+
+	suite.NoError(err)
+}
+
+func (suite *ContractTestSuite) TestCall() {
+	t := suite.call(10, 5)
+
+	suite.NoError(newJournal(suite.am, 2).apply(t))
+
+	receipt, _ := suite.am.getReceipt(util.HexEncode(t.Hash()))
+
+	suite.True(receipt.Success)
+	suite.Equal(int64(5), receipt.Return)
+	suite.Equal(int64(5), suite.am.load(suite.address, 0))
+	suite.True(ToCoin(10).Equal(suite.am.accounts[suite.address].Balance))
+}
+
+func (suite *ContractTestSuite) TestCallReverted() {
+	suite.NoError(newJournal(suite.am, 2).apply(suite.call(0, 5)))
+
+	t := suite.call(10, 0)
+
+	suite.NoError(newJournal(suite.am, 3).apply(t))
+
+	receipt, _ := suite.am.getReceipt(util.HexEncode(t.Hash()))
+
+	suite.False(receipt.Success)
+	suite.Equal(vm.ErrReverted.Error(), receipt.Error)
+	suite.Equal(int64(5), suite.am.load(suite.address, 0))
+	suite.True(ToCoin(100).Equal(suite.am.accounts["alice"].Balance))
+	suite.Equal(uint64(3), suite.am.accounts["alice"].Transactions)
+}
+
+func (suite *ContractTestSuite) TestCallGas() {
+	t := suite.call(10, 5)
+
+	suite.NoError(newJournal(suite.am, 2).charging(ToCoin(0), 0.01, "").apply(t))
+
+	receipt, _ := suite.am.getReceipt(util.HexEncode(t.Hash()))
+
+	// the sender pays for the gas used, and is refunded for the rest
+	suite.True(receipt.Success)
+	suite.Positive(receipt.Cost)
+	suite.True(ToCoin(receipt.Cost).Equal(ToCoin(float64(receipt.GasUsed) * 0.01)))
+	suite.True(ToCoin(90).Sub(receipt.Cost).Equal(suite.am.accounts["alice"].Balance))
+
+	supply, burned := suite.am.supply()
+
+	suite.True(ToCoin(100).Sub(receipt.Cost).Equal(supply))
+	suite.True(ToCoin(receipt.Cost).Equal(burned))
+}
+
+func (suite *ContractTestSuite) TestCallGasReverted() {
+	t := suite.call(10, 0)
+
+	suite.NoError(newJournal(suite.am, 2).charging(ToCoin(0), 0.01, "").apply(t))
+
+	receipt, _ := suite.am.getReceipt(util.HexEncode(t.Hash()))
+
+	// a failed execution still pays for its gas
+	suite.False(receipt.Success)
+	suite.Positive(receipt.Cost)
+	suite.True(ToCoin(100).Sub(receipt.Cost).Equal(suite.am.accounts["alice"].Balance))
+}
+
+func (suite *ContractTestSuite) TestCallGasFunds() {
+	t := suite.call(10, 5)
+	t.Data = string(util.JSONEncode(Invocation{Input: []int64{5}, Gas: 10000}))
+
+	// the sender cannot pay for all of the gas
+	suite.ErrorIs(newJournal(suite.am, 2).charging(ToCoin(0), 0.01, "").apply(t), ErrInvalidTransaction)
+}
+
+func (suite *ContractTestSuite) TestCallUnknownContract() {
+	t := suite.call(0, 1)
+	t.Receiver = "unknown"
+
+	suite.ErrorIs(newJournal(suite.am, 2).apply(t), ErrInvalidTransaction)
+}
+
+func (suite *ContractTestSuite) TestRollback() {
+	j := newJournal(suite.am, 2)
+
+	suite.NoError(j.apply(suite.call(0, 5)))
+	suite.NoError(j.apply(suite.call(0, 6)))
+
+	j.rollback()
+
+	contract, _ := suite.am.getContract(suite.address)
+
+	suite.Empty(contract.Storage)
+	suite.Len(suite.am.receipts, 1)
+}
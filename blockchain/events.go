@@ -0,0 +1,143 @@
+package blockchain
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// EventType is the type of an Event.
+type EventType string
+
+const (
+	BlockAdded     EventType = "blockAdded"
+	BlockReverted  EventType = "blockReverted"
+	BlockFinalized EventType = "blockFinalized"
+	TxAccepted     EventType = "txAccepted"
+	TxDropped      EventType = "txDropped"
+	StakeChanged   EventType = "stakeChanged"
+)
+
+// Event represents a change of the blockchain or its memory pool.
+// Block is set for block events, Transaction is set for transaction and stake events.
+type Event struct {
+	Type        EventType
+	Height      uint64
+	Block       *Block
+	Transaction *Transaction
+}
+
+// Filter decides whether an Event should be delivered to a Subscription.
+type Filter func(event Event) bool
+
+// Types returns a Filter that only accepts events of the given types.
+func Types(types ...EventType) Filter {
+	return func(event Event) bool {
+		for _, t := range types {
+			if event.Type == t {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Address returns a Filter that only accepts events of transactions sent or
+// received by the given address.
+func Address(address string) Filter {
+	return func(event Event) bool {
+		if event.Transaction == nil {
+			return false
+		}
+
+		return event.Transaction.Sender == address || event.Transaction.Receiver == address
+	}
+}
+
+// Subscription represents a subscription on the Bus.
+type Subscription struct {
+	Events  chan Event
+	id      uint64
+	bus     *Bus
+	filters []Filter
+}
+
+// Close closes the Subscription; no more events will be delivered.
+func (s *Subscription) Close() {
+	s.bus.unsubscribe(s.id)
+}
+
+// accepts checks whether the Event passes all filters of the Subscription.
+func (s *Subscription) accepts(event Event) bool {
+	for _, f := range s.filters {
+		if !f(event) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Bus is an in-process event bus on which the blockchain publishes its events.
+type Bus struct {
+	sync.RWMutex
+	subs map[uint64]*Subscription
+	next uint64
+}
+
+// newBus creates a new Bus.
+func newBus() *Bus {
+	return &Bus{
+		subs: make(map[uint64]*Subscription),
+	}
+}
+
+// Subscribe subscribes to all events that pass the given filters.
+// Events are buffered up to the given size; when the buffer of the Subscription is
+// full, new events are discarded rather than blocking the blockchain.
+func (bus *Bus) Subscribe(size int, filters ...Filter) *Subscription {
+	bus.Lock()
+	defer bus.Unlock()
+
+	s := &Subscription{
+		Events:  make(chan Event, size),
+		id:      bus.next,
+		bus:     bus,
+		filters: filters,
+	}
+
+	bus.subs[s.id] = s
+	bus.next++
+
+	return s
+}
+
+// unsubscribe removes the Subscription with given id, and closes its channel.
+func (bus *Bus) unsubscribe(id uint64) {
+	bus.Lock()
+	defer bus.Unlock()
+
+	if s, ok := bus.subs[id]; ok {
+		delete(bus.subs, id)
+		close(s.Events)
+	}
+}
+
+// publish delivers the Event to every Subscription that accepts it.
+func (bus *Bus) publish(event Event) {
+	bus.RLock()
+	defer bus.RUnlock()
+
+	for _, s := range bus.subs {
+		if !s.accepts(event) {
+			continue
+		}
+
+		select {
+		case s.Events <- event:
+		default:
+			log.Debug().Str("event", string(event.Type)).Msg("blockchain: subscription is full; discarded event")
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusFilters(t *testing.T) {
+	bus := newBus()
+
+	all := bus.Subscribe(10)
+	blocks := bus.Subscribe(10, Types(BlockAdded, BlockReverted))
+	alice := bus.Subscribe(10, Types(TxAccepted), Address("alice"))
+
+	bus.publish(Event{Type: BlockAdded, Block: &Block{}})
+	bus.publish(Event{Type: TxAccepted, Transaction: &Transaction{Sender: "alice"}})
+	bus.publish(Event{Type: TxAccepted, Transaction: &Transaction{Sender: "bob"}})
+
+	assert.Len(t, all.Events, 3)
+	assert.Len(t, blocks.Events, 1)
+	assert.Len(t, alice.Events, 1)
+}
+
+func TestBusFullSubscription(t *testing.T) {
+	bus := newBus()
+	s := bus.Subscribe(1)
+
+	bus.publish(Event{Type: TxAccepted})
+	bus.publish(Event{Type: TxDropped})
+
+	assert.Equal(t, TxAccepted, (<-s.Events).Type)
+
+	s.Close()
+
+	_, ok := <-s.Events
+
+	assert.False(t, ok)
+}
+
+func (suite *ValidationTestSuite) TestEvents() {
+	s := suite.bc.Events().Subscribe(10)
+	t := suite.transaction(100, 1)
+
+	suite.forge(t)
+
+	suite.Equal(TxAccepted, (<-s.Events).Type)
+
+	e := <-s.Events
+
+	suite.Equal(BlockAdded, e.Type)
+	suite.Equal(uint64(1), e.Height)
+
+	_, _ = suite.bc.RevertBlock()
+
+	suite.Equal(BlockReverted, (<-s.Events).Type)
+
+	suite.Require().NoError(suite.bc.DropTransaction(t))
+
+	suite.Equal(TxDropped, (<-s.Events).Type)
+}
@@ -0,0 +1,201 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ErrInvalidEvidence is the base error when evidence is invalid.
+var ErrInvalidEvidence = errors.New("invalid evidence")
+
+// Evidence proves that a validator equivocated; either it approved two different
+// blocks for the same proposal, in which case the evidence holds both votes, or it
+// proposed two different blocks for the same slot and rank, in which case the evidence
+// holds both signed blocks as proposals. Any validator can include evidence in a block,
+// after which the validator that equivocated is jailed and its stake is slashed.
+type Evidence struct {
+	First     Vote    `json:"first"`
+	Second    Vote    `json:"second"`
+	Proposals []Block `json:"proposals,omitempty"`
+}
+
+// Punishment is the punishment of a validator that equivocated. The validator is jailed
+// for the remainder of the current epoch and the given amount of epochs thereafter,
+// and the slash (a fraction) of every stake bonded to the validator is burned. A
+// validator that withholds the secret of the randomness beacon only loses the withhold
+// fraction of its stake. Unstaked funds remain slashable for the unbonding period, the
+// given amount of epochs after the current epoch, such that a validator cannot escape
+// its punishment by unstaking before the evidence is included.
+type Punishment struct {
+	Jail      uint64  `json:"jail"`
+	Slash     float64 `json:"slash"`
+	Withhold  float64 `json:"withhold"`
+	Unbonding uint64  `json:"unbonding"`
+}
+
+// Validator returns the validator that equivocated.
+func (e Evidence) Validator() string {
+	if len(e.Proposals) > 0 {
+		return e.Proposals[0].Validator
+	}
+
+	return e.First.Validator
+}
+
+// key identifies the proposal the validator equivocated on; a validator can only be
+// punished once for every proposal it voted on, and once for every proposal it made.
+func (e Evidence) key() string {
+	if len(e.Proposals) > 0 {
+		return fmt.Sprintf("%s:%d:%d:proposed", e.Proposals[0].Validator, e.Proposals[0].Slot, e.Proposals[0].Rank)
+	}
+
+	return fmt.Sprintf("%s:%d:%d", e.First.Validator, e.First.Slot, e.First.Rank)
+}
+
+// Verify verifies whether both votes have been signed by the same validator, and
+// approve different blocks for the same proposal; or whether both proposals have been
+// signed by the same validator, and are different blocks of the same slot and rank.
+func (e Evidence) Verify() error {
+	if len(e.Proposals) > 0 {
+		return e.verifyProposals()
+	}
+
+	a, b := e.First, e.Second
+
+	if a.Validator != b.Validator || a.Slot != b.Slot || a.Rank != b.Rank {
+		return fmt.Errorf("%w: votes are not on the same proposal", ErrInvalidEvidence)
+	}
+
+	if !a.Valid || !b.Valid || a.Block == b.Block {
+		return fmt.Errorf("%w: votes do not conflict", ErrInvalidEvidence)
+	}
+
+	if a.Verify() != nil || b.Verify() != nil {
+		return fmt.Errorf("%w: invalid vote signature", ErrInvalidEvidence)
+	}
+
+	return nil
+}
+
+// verifyProposals verifies the proposals of the evidence; see Verify.
+func (e Evidence) verifyProposals() error {
+	if len(e.Proposals) != 2 {
+		return fmt.Errorf("%w: evidence should hold two proposals", ErrInvalidEvidence)
+	}
+
+	a, b := e.Proposals[0], e.Proposals[1]
+
+	if a.Validator != b.Validator || a.Slot != b.Slot || a.Rank != b.Rank {
+		return fmt.Errorf("%w: blocks are not of the same proposal", ErrInvalidEvidence)
+	}
+
+	if a.string() == b.string() {
+		return fmt.Errorf("%w: blocks do not conflict", ErrInvalidEvidence)
+	}
+
+	if a.VerifySignature() != nil || b.VerifySignature() != nil {
+		return fmt.Errorf("%w: invalid block signature", ErrInvalidEvidence)
+	}
+
+	return nil
+}
+
+// validateEvidence validates the evidence of the block, and punishes the validators
+// that equivocated through the given journal.
+func validateEvidence(j *journal, block Block, punishment Punishment) error {
+	for _, e := range block.Evidence {
+		if err := j.punish(e, punishment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// punish jails the validator of the evidence, and slashes the stakes bonded to it.
+func (j *journal) punish(e Evidence, punishment Punishment) error {
+	if err := e.Verify(); err != nil {
+		return err
+	}
+
+	if j.am.isPunished(e.key()) {
+		return fmt.Errorf("%w: validator has already been punished", ErrInvalidEvidence)
+	}
+
+	j.punished(e.key())
+	j.jail(e.Validator(), j.am.currentEpoch()+punishment.Jail)
+	j.slash(e.Validator(), punishment.Slash)
+
+	return nil
+}
+
+// slash slashes the fraction of every stake bonded to the validator, and of every
+// stake that is being unbonded from it.
+func (j *journal) slash(validator string, fraction float64) {
+	for _, delegator := range j.am.delegators(validator) {
+		stake := j.am.getBond(delegator, validator).Float64()
+
+		// the slashed stake is burned; it is rounded down to whole cents
+		if slashed := math.Floor(stake*fraction*100) / 100; slashed > 0 {
+			j.bond(delegator, validator, -slashed)
+			j.burn(slashed)
+		}
+	}
+
+	for _, k := range j.am.unbondingFrom(validator) {
+		stake := j.am.getUnbonding(k).Float64()
+
+		if slashed := math.Floor(stake*fraction*100) / 100; slashed > 0 {
+			j.unbond(k, -slashed)
+			j.burn(slashed)
+		}
+	}
+}
+
+// SetPunishment sets the punishment of validators that equivocated.
+func (b *Blockchain) SetPunishment(punishment Punishment) {
+	b.punishment = punishment
+}
+
+// ReportEvidence adds the evidence to the evidence pool, such that it will be included
+// in the next block that is created by this node.
+func (b *Blockchain) ReportEvidence(e Evidence) error {
+	if err := e.Verify(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.evidence[e.key()]; ok || b.am.isPunished(e.key()) {
+		return fmt.Errorf("%w: evidence has already been reported", ErrInvalidEvidence)
+	}
+
+	b.evidence[e.key()] = e
+
+	return nil
+}
+
+// pendingEvidence returns the evidence within the evidence pool of validators that
+// have not been punished yet, ordered by key.
+func (b *Blockchain) pendingEvidence() []Evidence {
+	pending := make([]Evidence, 0, len(b.evidence))
+
+	for k, e := range b.evidence {
+		if b.am.isPunished(k) {
+			delete(b.evidence, k)
+
+			continue
+		}
+
+		pending = append(pending, e)
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].key() < pending[j].key()
+	})
+
+	return pending
+}
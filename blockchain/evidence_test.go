@@ -0,0 +1,144 @@
+package blockchain
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"backend/crypto"
+	"backend/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signer creates a new validator, and returns its id, its encoded consensus key and
+// a function that signs votes.
+func signer(t *testing.T) (string, string, func(block Block) Vote) {
+	t.Helper()
+
+	priv, validator := newValidator(t)
+
+	return validator, util.HexEncode(crypto.EncodePublicKey(&priv.PublicKey)), func(block Block) Vote {
+		v := NewVote(validator, block, true)
+		_ = v.Sign(priv)
+
+		return v
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	_, _, sign := signer(t)
+	_, _, other := signer(t)
+
+	a := Block{Validator: "a", Height: 1, Slot: 1}
+	b := Block{Validator: "b", Height: 1, Slot: 1}
+
+	assert.NoError(t, Evidence{First: sign(a), Second: sign(b)}.Verify())
+
+	// the same block
+	assert.ErrorIs(t, Evidence{First: sign(a), Second: sign(a)}.Verify(), ErrInvalidEvidence)
+
+	// different validators
+	assert.ErrorIs(t, Evidence{First: sign(a), Second: other(b)}.Verify(), ErrInvalidEvidence)
+
+	// different proposals
+	b.Rank = 1
+	assert.ErrorIs(t, Evidence{First: sign(a), Second: sign(b)}.Verify(), ErrInvalidEvidence)
+
+	// forged votes
+	b.Rank = 0
+	v := other(b)
+	v.Validator = sign(b).Validator
+
+	assert.ErrorIs(t, Evidence{First: sign(a), Second: v}.Verify(), ErrInvalidEvidence)
+}
+
+func TestProposalEvidence(t *testing.T) {
+	priv, validator := newValidator(t)
+	other, _ := newValidator(t)
+
+	propose := func(key *ecdsa.PrivateKey, timestamp int64) Block {
+		b := Block{Validator: validator, Height: 1, Slot: 1, Timestamp: timestamp}
+		require.NoError(t, b.Sign(key))
+
+		return b
+	}
+
+	a, b := propose(priv, 1), propose(priv, 2)
+	e := Evidence{Proposals: []Block{a, b}}
+
+	assert.NoError(t, e.Verify())
+	assert.Equal(t, validator, e.Validator())
+	assert.NotEqual(t, Evidence{First: NewVote(validator, a, true)}.key(), e.key())
+
+	// the same block, even with another certificate
+	c := a
+	c.Certificate = []Vote{NewVote(validator, a, true)}
+	assert.ErrorIs(t, Evidence{Proposals: []Block{a, c}}.Verify(), ErrInvalidEvidence)
+
+	// a single proposal
+	assert.ErrorIs(t, Evidence{Proposals: []Block{a}}.Verify(), ErrInvalidEvidence)
+
+	// different ranks
+	c = propose(priv, 2)
+	c.Rank = 1
+	require.NoError(t, c.Sign(priv))
+	assert.ErrorIs(t, Evidence{Proposals: []Block{a, c}}.Verify(), ErrInvalidEvidence)
+
+	// a forged proposal
+	assert.ErrorIs(t, Evidence{Proposals: []Block{a, propose(other, 2)}}.Verify(), ErrInvalidEvidence)
+}
+
+func (suite *ValidationTestSuite) TestPunishment() {
+	validator, key, sign := signer(suite.T())
+
+	t := suite.transaction(10, 1)
+	t.Receiver = ""
+	t.Type = Stake
+	t.Data = string(util.JSONEncode(Bond{Validator: validator, Key: key}))
+	t = suite.sign(suite.priv, t)
+
+	suite.forge(t)
+	suite.bc.SetPunishment(Punishment{Jail: 1, Slash: 0.25})
+
+	supply, _ := suite.bc.Supply()
+
+	e := Evidence{
+		First:  sign(Block{Validator: "a", Height: 2, Slot: 2}),
+		Second: sign(Block{Validator: "b", Height: 2, Slot: 2}),
+	}
+
+	suite.NoError(suite.bc.ReportEvidence(e))
+	suite.ErrorIs(suite.bc.ReportEvidence(e), ErrInvalidEvidence)
+
+	block := suite.forge(suite.transaction(1, 2))
+
+	suite.Equal([]Evidence{e}, block.Evidence)
+
+	// the validator is jailed for the current and the next epoch, and its stake is slashed
+	suite.Empty(suite.bc.Validators(defaultEpoch))
+	suite.True(ToCoin(7.5).Equal(suite.bc.Validators(2 * defaultEpoch)[validator]))
+
+	// the slashed stake is burned
+	circulating, burned := suite.bc.Supply()
+
+	suite.True(ToCoin(2.5).Equal(burned))
+	suite.True(supply.Equal(circulating.Add(burned.Float64())))
+
+	// a validator is only punished once for the same proposal
+	suite.ErrorIs(suite.bc.ReportEvidence(e), ErrInvalidEvidence)
+
+	_, err := suite.bc.RevertBlock()
+
+	suite.NoError(err)
+	suite.True(ToCoin(10).Equal(suite.bc.Validators(defaultEpoch)[validator]))
+
+	_, burned = suite.bc.Supply()
+
+	suite.True(ToCoin(0).Equal(burned))
+
+	// the evidence and the transactions of the reverted block are returned to the pools
+	block = suite.forge()
+
+	suite.Equal([]Evidence{e}, block.Evidence)
+}
@@ -1,11 +1,182 @@
 package blockchain
 
-const feePercentage = 0.01
+import (
+	"fmt"
+	"sort"
 
-// note: this is not (yet) implemented
+	"github.com/shopspring/decimal"
+)
 
-func calculateFee(c Coin) Coin {
-	fee := c.Float64() * feePercentage
+// feeHistory is the amount of recent blocks from which the tip of an estimate is derived.
+const feeHistory = 20
 
-	return ToCoin(fee)
+// FeeMarket holds the parameters of the base fee. Every transaction pays the base fee
+// of its block, which is burned, along with its tip, which is credited to the validator
+// of the block. The base fee of a block follows from how full the last block was: when
+// the last block held more transactions than the target, the base fee rises by at most
+// 1/Denominator, and when it held less, the base fee falls by at most 1/Denominator, down
+// to the minimum. Fees are not charged when the target is zero. Deploy and Call
+// transactions also pay the gas they use at the gas price, which is burned as well.
+type FeeMarket struct {
+	Target      int     `json:"target"`
+	Denominator int     `json:"denominator"`
+	Initial     float64 `json:"initial"`
+	Minimum     float64 `json:"minimum"`
+	GasPrice    float64 `json:"gasPrice"`
+}
+
+// FeeEstimate holds the base fee of the next block, and the tip that is likely to get a
+// transaction into it; the fee of a transaction is the sum of both.
+type FeeEstimate struct {
+	BaseFee float64 `json:"baseFee"`
+	Tip     float64 `json:"tip"`
+	Fee     float64 `json:"fee"`
+}
+
+// next returns the base fee of the block that follows the last block. Coins are
+// rounded to cents, so the base fee rises by at least a cent while blocks are fuller
+// than the target.
+func (f FeeMarket) next(last Block) Coin {
+	if f.Target <= 0 {
+		return ToCoin(0)
+	}
+
+	if last.Height == 0 {
+		return f.initial()
+	}
+
+	// the base fee changes at once when no denominator has been set
+	denominator := f.Denominator
+	if denominator < 1 {
+		denominator = 1
+	}
+
+	base := decimal.NewFromFloatWithExponent(last.BaseFee, -2)
+	used := int64(len(last.Transactions)) - int64(f.Target)
+
+	delta := base.Mul(decimal.NewFromInt(used)).Div(decimal.NewFromInt(int64(f.Target * denominator))).Round(2)
+
+	if cent := decimal.New(1, -2); used > 0 && delta.LessThan(cent) {
+		delta = cent
+	}
+
+	if next := base.Add(delta); next.GreaterThan(decimal.NewFromFloatWithExponent(f.Minimum, -2)) {
+		return Coin{next}
+	}
+
+	return ToCoin(f.Minimum)
+}
+
+// initial returns the base fee of the first block in which fees are charged.
+func (f FeeMarket) initial() Coin {
+	if f.Target <= 0 {
+		return ToCoin(0)
+	}
+
+	return ToCoin(f.Initial)
+}
+
+// baseFee returns the base fee of the block that follows the last block; the fee market
+// starts at its initial base fee once the fees fork is activated.
+func (p params) baseFee(last Block) Coin {
+	if !p.schedule.Rules(last.Height + 1).Active(FeesFork) {
+		return ToCoin(0)
+	}
+
+	if !p.schedule.Rules(last.Height).Active(FeesFork) {
+		return p.fees.initial()
+	}
+
+	return p.fees.next(last)
+}
+
+// free checks whether the transaction pays no fees; the duties of validators are free,
+// as validators might hold no balance besides their stake.
+func (t Transaction) free() bool {
+	switch t.Type {
+	case Unjail, Commitment, Reveal, Ballot:
+		return true
+	case Stake, Unstake, Regular, Reward, Fee, Penalty, Exchange, Lock, Claim, Refund, Deploy, Call, Register, Renew, Transfer, Propose:
+	}
+
+	return false
+}
+
+// fee returns the fee the transaction offers to the validator of its block; its tip.
+func (t Transaction) fee() Coin {
+	return ToCoin(t.Tip)
+}
+
+// pay charges the sender of the transaction the base fee and the tip; the base fee is
+// burned, while the tip is credited to the validator of the block. The tip of a
+// transaction that is not yet in a block is credited to no one.
+func (j *journal) pay(transaction Transaction) error {
+	if transaction.free() || !j.rules.Active(FeesFork) {
+		return nil
+	}
+
+	if 0 > transaction.Tip {
+		return fmt.Errorf("%w: negative tip", ErrInvalidTransaction)
+	}
+
+	total := j.baseFee.Add(transaction.Tip)
+	if total.Equal(ToCoin(0)) {
+		return nil
+	}
+
+	if err := j.debit(transaction.Sender, total.Float64(), false); err != nil {
+		return err
+	}
+
+	if !j.baseFee.Equal(ToCoin(0)) {
+		j.burn(j.baseFee.Float64())
+	}
+
+	if len(j.forger) > 0 && !transaction.fee().Equal(ToCoin(0)) {
+		j.credit(j.forger, transaction.fee().Float64())
+	}
+
+	return nil
+}
+
+// SetFeeMarket sets the parameters of the base fee.
+func (b *Blockchain) SetFeeMarket(fees FeeMarket) {
+	b.fees = fees
+}
+
+// BaseFee returns the base fee of the next block.
+func (b *Blockchain) BaseFee() Coin {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.governed().baseFee(b.Blocks[len(b.Blocks)-1])
+}
+
+// EstimateFee returns the base fee of the next block, along with the median tip of the
+// transactions within the recent blocks.
+func (b *Blockchain) EstimateFee() FeeEstimate {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	last := b.Blocks[len(b.Blocks)-1]
+	tips := make([]float64, 0)
+
+	for i := len(b.Blocks) - 1; i > 0 && i >= len(b.Blocks)-feeHistory; i-- {
+		for _, t := range b.Blocks[i].Transactions {
+			if !t.free() {
+				tips = append(tips, t.Tip)
+			}
+		}
+	}
+
+	var tip float64
+
+	if len(tips) > 0 {
+		sort.Float64s(tips)
+		tip = tips[len(tips)/2]
+	}
+
+	base := b.governed().baseFee(last)
+
+	return FeeEstimate{BaseFee: base.Float64(), Tip: tip, Fee: base.Add(tip).Float64()}
 }
@@ -0,0 +1,65 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeMarket(t *testing.T) {
+	f := FeeMarket{Target: 2, Denominator: 8, Initial: 1, Minimum: 0.5}
+	full := Block{Height: 1, BaseFee: 1, Transactions: make([]Transaction, 4)}
+
+	assert.True(t, ToCoin(1).Equal(f.next(Block{})))
+	assert.True(t, ToCoin(1.06).Equal(f.next(Block{Height: 1, BaseFee: 1, Transactions: make([]Transaction, 3)})))
+	assert.True(t, ToCoin(1.13).Equal(f.next(full)))
+
+	// the base fee falls down to the minimum, but rises by at least a cent
+	assert.True(t, ToCoin(0.5).Equal(f.next(Block{Height: 1, BaseFee: 0.5})))
+
+	f.Denominator = 100
+	assert.True(t, ToCoin(0.51).Equal(f.next(Block{Height: 1, BaseFee: 0.5, Transactions: make([]Transaction, 3)})))
+
+	// fees are not charged without a target
+	assert.True(t, ToCoin(0).Equal(FeeMarket{}.next(full)))
+}
+
+func (suite *ValidationTestSuite) TestFees() {
+	suite.bc.SetFeeMarket(FeeMarket{Target: 1, Denominator: 4, Initial: 1, Minimum: 1})
+
+	t := suite.transaction(10, 1)
+	t.Tip = 2
+	t = suite.sign(suite.priv, t)
+
+	// the base fee is burned, while the tip is paid to the validator of the block
+	block := suite.forge(t)
+	_, burned := suite.bc.Supply()
+
+	account, err := suite.bc.GetAccount(suite.validator)
+	suite.Require().NoError(err)
+
+	suite.Equal(1.0, block.BaseFee)
+	suite.True(ToCoin(1).Equal(burned))
+	suite.True(ToCoin(2).Equal(account.Balance))
+
+	// the base fee rises once blocks are fuller than the target
+	suite.forge(suite.transaction(10, 2), suite.transaction(10, 3), suite.transaction(10, 4))
+
+	estimate := suite.bc.EstimateFee()
+
+	suite.True(ToCoin(1.5).Equal(suite.bc.BaseFee()))
+	suite.Equal(1.5, estimate.BaseFee)
+	suite.Equal(estimate.BaseFee+estimate.Tip, estimate.Fee)
+
+	// blocks should charge the base fee that follows from the last block
+	invalid := suite.block(suite.key, suite.bc.Last(), suite.bc.Last().Slot+1, suite.transaction(10, 5))
+
+	suite.ErrorIs(suite.bc.AddBlock(invalid), ErrInvalidBlock)
+
+	// the tip should not be negative
+	t = suite.transaction(10, 5)
+	t.Tip = -1
+	t = suite.sign(suite.priv, t)
+
+	suite.ErrorIs(suite.bc.UpdateMempool(t), ErrInvalidTransaction)
+}
@@ -0,0 +1,79 @@
+package blockchain
+
+import (
+	"backend/util"
+
+	"github.com/rs/zerolog/log"
+)
+
+// checkpointInterval is the amount of blocks between two checkpoints.
+const checkpointInterval uint64 = 16
+
+// Checkpoint is a block whose height is a multiple of the checkpoint interval.
+// A checkpoint is justified once it has been added with a certificate of a stake
+// supermajority, and finalized once the next checkpoint has been justified as well.
+type Checkpoint struct {
+	Height uint64 `json:"height"`
+	Hash   string `json:"hash"`
+}
+
+// checkpoint returns the Checkpoint of the block.
+func checkpoint(block Block) Checkpoint {
+	return Checkpoint{Height: block.Height, Hash: util.HexEncode(block.Hash())}
+}
+
+// includes checks whether the blocks include the checkpoint; every chain includes
+// the empty checkpoint.
+func includes(blocks []Block, c Checkpoint) bool {
+	if len(c.Hash) == 0 {
+		return true
+	}
+
+	return uint64(len(blocks)) > c.Height && checkpoint(blocks[c.Height]) == c
+}
+
+// justify justifies the block if it is a checkpoint whose certificate has been verified
+// to reach the quorum of the validators; the blocks of the bootstrap epoch and before
+// the certificate fork are not certified. When the justified checkpoint directly
+// follows the previously justified checkpoint, the latter will be finalized.
+func (b *Blockchain) justify(block Block, certified bool) {
+	if !certified || block.Height%checkpointInterval != 0 {
+		return
+	}
+
+	prev := b.justified
+	b.justified = checkpoint(block)
+
+	if prev.Height+checkpointInterval == block.Height && prev.Height > b.Finalized.Height {
+		b.finalize(prev)
+	}
+}
+
+// finalize finalizes the checkpoint; the changes of the finalized blocks are
+// committed, as the blocks can no longer be reverted.
+func (b *Blockchain) finalize(c Checkpoint) {
+	b.Finalized = c
+
+	// the journals belong to the last blocks of the blockchain
+	first := uint64(len(b.Blocks) - len(b.journals))
+
+	for len(b.journals) > 0 && c.Height >= first {
+		b.journals[0].commit()
+		b.journals = b.journals[1:]
+		first++
+	}
+
+	block := b.Blocks[c.Height]
+
+	b.bus.publish(Event{Type: BlockFinalized, Height: c.Height, Block: &block})
+
+	log.Info().Uint64("height", c.Height).Msg("blockchain: finalized checkpoint")
+}
+
+// Finality returns the last justified and the last finalized checkpoint.
+func (b *Blockchain) Finality() (Checkpoint, Checkpoint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.justified, b.Finalized
+}
@@ -0,0 +1,78 @@
+package blockchain
+
+func (suite *ValidationTestSuite) TestFinality() {
+	for i := uint64(1); i <= checkpointInterval; i++ {
+		suite.forge(suite.transaction(1, i))
+	}
+
+	justified, finalized := suite.bc.Finality()
+
+	suite.Equal(checkpointInterval, justified.Height)
+	suite.Equal(uint64(0), finalized.Height)
+
+	for i := checkpointInterval + 1; i <= 2*checkpointInterval; i++ {
+		suite.forge(suite.transaction(1, i))
+	}
+
+	justified, finalized = suite.bc.Finality()
+
+	suite.Equal(2*checkpointInterval, justified.Height)
+	suite.Equal(checkpointInterval, finalized.Height)
+
+	// blocks after the finalized checkpoint can be reverted, the checkpoint itself cannot
+	for i := checkpointInterval + 1; i <= 2*checkpointInterval; i++ {
+		_, err := suite.bc.RevertBlock()
+		suite.Require().NoError(err)
+	}
+
+	_, err := suite.bc.RevertBlock()
+
+	suite.Error(err)
+	suite.Equal(checkpointInterval, suite.bc.Last().Height)
+
+	justified, _ = suite.bc.Finality()
+
+	suite.Equal(checkpointInterval, justified.Height)
+}
+
+func (suite *ValidationTestSuite) TestFinalityUncertified() {
+	// certificates are not verified before the certificate fork, so no checkpoint is justified
+	suite.bc.SetSchedule(Schedule{CertificateFork: 4 * checkpointInterval})
+
+	for i := uint64(1); i <= 2*checkpointInterval; i++ {
+		suite.forge(suite.transaction(1, i))
+	}
+
+	justified, finalized := suite.bc.Finality()
+
+	suite.Equal(uint64(0), justified.Height)
+	suite.Equal(uint64(0), finalized.Height)
+}
+
+func (suite *ValidationTestSuite) TestInitFinalized() {
+	for i := uint64(1); i <= 2*checkpointInterval; i++ {
+		suite.forge(suite.transaction(1, i))
+	}
+
+	finalized := append([]Block{}, suite.bc.Blocks...)
+
+	// a longer chain that does not include the finalized checkpoint
+	other, _ := newValidator(suite.T())
+
+	bc := NewBlockchain()
+	bc.Init("validator")
+
+	for i := uint64(1); i <= 2*checkpointInterval+1; i++ {
+		suite.Require().NoError(bc.UpdateMempool(suite.transaction(1, i)))
+
+		block, err := bc.CreateBlock(other, 0)
+		suite.Require().NoError(err)
+		suite.Require().NoError(bc.AddBlock(block))
+	}
+
+	restored := NewBlockchain()
+	restored.Finalized = suite.bc.Finalized
+	restored.Init("validator", bc.Blocks, finalized)
+
+	suite.Equal(finalized, restored.Blocks)
+}
@@ -0,0 +1,68 @@
+package blockchain
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+)
+
+// sortCandidates sorts the candidate chains in order of fork choice. The longest
+// chain is preferred; of chains with the same length, the chain whose blocks have
+// been proposed by the lowest ranks is preferred.
+func sortCandidates(candidates [][]Block) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if len(candidates[i]) != len(candidates[j]) {
+			return len(candidates[i]) > len(candidates[j])
+		}
+
+		return rank(candidates[i]) < rank(candidates[j])
+	})
+}
+
+// rank returns the sum of the ranks of the blocks.
+func rank(blocks []Block) uint64 {
+	var sum uint64
+
+	for _, b := range blocks {
+		sum += uint64(b.Rank)
+	}
+
+	return sum
+}
+
+// competes checks whether the block competes with the last block; both follow the
+// same parent within the same slot. Ranks are only comparable within a slot, as every
+// slot elects its own proposers.
+func (b *Blockchain) competes(block Block) bool {
+	last := b.Blocks[len(b.Blocks)-1]
+
+	return len(b.Blocks) > 1 && block.Height == last.Height && block.Slot == last.Slot && block.PrevHash == last.PrevHash
+}
+
+// replace replaces the last block with the competing block, if the latter has been
+// proposed by a lower rank. The last block is restored when the competing block is
+// invalid. The reserved transactions of the memory pool should be released beforehand.
+func (b *Blockchain) replace(block Block) error {
+	last := b.Blocks[len(b.Blocks)-1]
+
+	if block.Rank >= last.Rank {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "block is outranked by last block")
+	}
+
+	if _, err := b.revert(); err != nil {
+		return err
+	}
+
+	if err := b.add(block); err != nil {
+		if err := b.add(last); err != nil {
+			log.Error().Err(err).Msg("blockchain: failed to restore outranked block")
+		}
+
+		return err
+	}
+
+	log.Info().Uint32("rank", block.Rank).Msg("blockchain: replaced block of higher rank")
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package blockchain
+
+func (suite *ValidationTestSuite) TestReplaceByRank() {
+	suite.Require().NoError(suite.bc.UpdateMempool(suite.transaction(100, 1)))
+
+	key, _ := newValidator(suite.T())
+
+	backup, err := suite.bc.CreateBlock(key, 1)
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.bc.AddBlock(backup))
+
+	genesis := suite.bc.Blocks[0]
+
+	// an invalid block does not replace the last block, even though it outranks it
+	invalid := suite.block(suite.key, genesis, backup.Slot, suite.transaction(50, 7))
+
+	suite.ErrorIs(suite.bc.AddBlock(invalid), ErrInvalidBlock)
+	suite.Equal(backup, suite.bc.Last())
+
+	// a block of another slot does not compete, even though it has a lower rank
+	later := suite.block(suite.key, genesis, backup.Slot+1, suite.transaction(50, 1))
+
+	suite.ErrorIs(suite.bc.AddBlock(later), ErrInvalidBlock)
+	suite.Equal(backup, suite.bc.Last())
+
+	primary := suite.block(suite.key, genesis, backup.Slot, suite.transaction(50, 1))
+
+	suite.NoError(suite.bc.AddBlock(primary))
+	suite.Equal(primary, suite.bc.Last())
+
+	// the block of the backup is outranked
+	suite.ErrorIs(suite.bc.AddBlock(backup), ErrInvalidBlock)
+	suite.Equal(primary, suite.bc.Last())
+
+	account, err := suite.bc.GetAccount(suite.wallet)
+
+	suite.NoError(err)
+	suite.True(ToCoin(50).Equal(account.Balance))
+}
+
+func (suite *ValidationTestSuite) TestElectedRank() {
+	key, backup := newValidator(suite.T())
+
+	suite.bc.SetElection(func(seed []byte, slot uint64, stakes map[string]Coin) ([]string, error) {
+		return []string{suite.validator, backup}, nil
+	})
+
+	block := suite.block(key, suite.bc.Last(), 1, suite.transaction(100, 1))
+
+	suite.ErrorIs(suite.bc.ValidateBlock(block), ErrInvalidBlock)
+
+	// the rank is part of the signed header
+	block.Rank = 1
+
+	suite.ErrorIs(suite.bc.ValidateBlock(block), ErrInvalidBlock)
+	suite.Require().NoError(block.Sign(key))
+	suite.NoError(suite.bc.ValidateBlock(block))
+
+	block.Rank = Ranks
+
+	suite.Require().NoError(block.Sign(key))
+	suite.ErrorIs(suite.bc.ValidateBlock(block), ErrInvalidBlock)
+}
+
+func (suite *ValidationTestSuite) TestSortCandidates() {
+	short := []Block{{}}
+	primary := []Block{{}, {Rank: 0}}
+	backup := []Block{{}, {Rank: 2}}
+
+	candidates := [][]Block{short, backup, primary}
+
+	sortCandidates(candidates)
+
+	suite.Equal([][]Block{primary, backup, short}, candidates)
+}
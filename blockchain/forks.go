@@ -0,0 +1,132 @@
+package blockchain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"backend/errors"
+)
+
+// Fork is a protocol upgrade that changes the rules from a coordinated height onwards.
+type Fork string
+
+const (
+	// BeaconFork elects the proposers by the seed of the randomness beacon, and accepts
+	// Commitment and Reveal transactions; before it, proposers are elected by slot alone.
+	BeaconFork Fork = "beacon"
+	// FeesFork charges the base fee and the tip of every transaction; before it, fees are not charged.
+	FeesFork Fork = "fees"
+	// GovernanceFork accepts Propose and Ballot transactions, and activates the proposals that passed.
+	GovernanceFork Fork = "governance"
+	// RanksFork accepts the blocks of backup proposers; before it, only the primary proposer of a slot forges its block.
+	RanksFork Fork = "ranks"
+	// CertificateFork requires every block to be certified by the quorum of the validators; before it, certificates are not verified.
+	CertificateFork Fork = "certificate"
+)
+
+// forks holds every known fork.
+var forks = []Fork{BeaconFork, FeesFork, GovernanceFork, RanksFork, CertificateFork}
+
+// Schedule maps forks to the height of the first block in which they are active. Forks
+// that are not scheduled are active from genesis onwards, such that a new network runs
+// the latest rules, while a running network schedules an upgrade at a future height.
+type Schedule map[Fork]uint64
+
+// Rules holds whether every known fork is active at a height.
+type Rules struct {
+	Height uint64        `json:"height"`
+	Forks  map[Fork]bool `json:"forks"`
+}
+
+// Rules returns the rules at the given height.
+func (s Schedule) Rules(height uint64) Rules {
+	r := Rules{Height: height, Forks: make(map[Fork]bool, len(forks))}
+
+	for _, f := range forks {
+		r.Forks[f] = s[f] <= height
+	}
+
+	return r
+}
+
+// Active checks whether the fork is active; the zero Rules activate every fork.
+func (r Rules) Active(f Fork) bool {
+	active, ok := r.Forks[f]
+
+	return !ok || active
+}
+
+// ParseSchedule parses a comma separated list of forks along with their activation
+// height, such as "fees=1000,governance=2000".
+func ParseSchedule(s string) (Schedule, error) {
+	schedule := make(Schedule)
+
+	for _, entry := range strings.Split(s, ",") {
+		if entry = strings.TrimSpace(entry); len(entry) == 0 {
+			continue
+		}
+
+		name, height, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, errors.ErrInvalidArgument("fork %s has no height", entry)
+		}
+
+		f := Fork(strings.TrimSpace(name))
+		if !f.known() {
+			return nil, errors.ErrInvalidArgument("unknown fork %s", f)
+		}
+
+		h, err := strconv.ParseUint(strings.TrimSpace(height), 10, 64)
+		if err != nil {
+			return nil, errors.ErrInvalidArgument("invalid height of fork %s", f)
+		}
+
+		schedule[f] = h
+	}
+
+	return schedule, nil
+}
+
+// known checks whether the fork is known.
+func (f Fork) known() bool {
+	for _, k := range forks {
+		if k == f {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fork returns the fork that activates the type of the transaction, if any.
+func (t Transaction) fork() (Fork, bool) {
+	switch t.Type {
+	case Commitment, Reveal:
+		return BeaconFork, true
+	case Propose, Ballot:
+		return GovernanceFork, true
+	case Stake, Unstake, Regular, Reward, Fee, Penalty, Exchange, Lock, Claim, Refund, Deploy, Call, Register, Renew, Transfer, Unjail:
+	}
+
+	return "", false
+}
+
+// allowed checks whether the type of the transaction is active under the rules of the journal.
+func (j *journal) allowed(transaction Transaction) error {
+	if f, ok := transaction.fork(); ok && !j.rules.Active(f) {
+		return fmt.Errorf("%w: %s transactions are not active before the %s fork", ErrInvalidTransaction, transaction.Type, f)
+	}
+
+	return nil
+}
+
+// SetSchedule sets the heights at which forks are activated.
+func (b *Blockchain) SetSchedule(schedule Schedule) {
+	b.schedule = schedule
+}
+
+// Rules returns the rules of the block at the given height.
+func (b *Blockchain) Rules(height uint64) Rules {
+	return b.schedule.Rules(height)
+}
@@ -0,0 +1,55 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	s, err := ParseSchedule(" fees=10, governance = 20 ")
+	assert.NoError(t, err)
+	assert.Equal(t, Schedule{FeesFork: 10, GovernanceFork: 20}, s)
+
+	// unscheduled forks are active from genesis
+	assert.True(t, s.Rules(0).Active(BeaconFork))
+	assert.False(t, s.Rules(9).Active(FeesFork))
+	assert.True(t, s.Rules(10).Active(FeesFork))
+	assert.False(t, s.Rules(10).Active(GovernanceFork))
+	assert.True(t, Rules{}.Active(GovernanceFork))
+
+	for _, invalid := range []string{"fees", "unknown=1", "fees=-1"} {
+		_, err = ParseSchedule(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func (suite *ValidationTestSuite) TestForks() {
+	suite.bc.SetFeeMarket(FeeMarket{Target: 1, Denominator: 8, Initial: 1, Minimum: 1})
+	suite.bc.SetSchedule(Schedule{BeaconFork: 100, FeesFork: 2, GovernanceFork: 100})
+
+	// fees are charged from the fork onwards, starting at the initial base fee
+	block := suite.forge(suite.transaction(1, 1))
+	_, burned := suite.bc.Supply()
+
+	suite.Equal(0.0, block.BaseFee)
+	suite.Empty(block.Beacon)
+	suite.True(ToCoin(0).Equal(burned))
+
+	block = suite.forge(suite.transaction(1, 2))
+	_, burned = suite.bc.Supply()
+
+	suite.Equal(1.0, block.BaseFee)
+	suite.True(ToCoin(1).Equal(burned))
+	suite.True(suite.bc.Rules(2).Active(FeesFork))
+
+	// blocks should charge the base fee once the fork is active
+	invalid := suite.block(suite.key, suite.bc.Last(), suite.bc.Last().Slot+1, suite.transaction(1, 3))
+
+	suite.ErrorIs(suite.bc.AddBlock(invalid), ErrInvalidBlock)
+
+	// governance transactions are not accepted before their fork
+	key, _ := newValidator(suite.T())
+
+	suite.ErrorContains(suite.bc.UpdateMempool(suite.validatorTransaction(key, 0, Ballot, Choice{})), "governance fork")
+}
@@ -0,0 +1,341 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"backend/util"
+
+	"github.com/shopspring/decimal"
+)
+
+// votingPeriod is the amount of blocks after its submission in which a proposal can be voted on.
+const votingPeriod = 64
+
+// Proposal holds the parameters of a Propose transaction; it proposes to change the
+// parameter to the value from the block at the activation height onwards. Validators
+// vote on the proposal until its deadline, which is the end of the voting period. The
+// proposal passes when the validators that approve it hold more than two thirds of the
+// stake at the activation height, after which every node switches over at that block.
+type Proposal struct {
+	Parameter  string          `json:"parameter"`
+	Value      float64         `json:"value"`
+	Activation uint64          `json:"activation"`
+	Proposer   string          `json:"proposer,omitempty"`
+	Deadline   uint64          `json:"deadline,omitempty"`
+	Votes      map[string]bool `json:"votes,omitempty"`
+}
+
+// Choice holds the parameters of a Ballot transaction; the proposal is the hash of its
+// Propose transaction.
+type Choice struct {
+	Proposal string `json:"proposal"`
+	Approve  bool   `json:"approve"`
+}
+
+// parameter is a protocol parameter that can be changed through governance.
+type parameter struct {
+	valid func(v float64) bool
+	get   func(p params) float64
+	set   func(p *params, v float64)
+}
+
+// whole checks whether the value is a positive whole number, or zero.
+func whole(v float64) bool {
+	return v >= 0 && v == math.Trunc(v) && v <= math.MaxInt32
+}
+
+// fraction checks whether the value is a fraction between zero and one.
+func fraction(v float64) bool {
+	return v >= 0 && v <= 1
+}
+
+// parameters holds the protocol parameters that can be changed through governance.
+var parameters = map[string]parameter{
+	"block.bytes": {
+		valid: whole,
+		get:   func(p params) float64 { return float64(p.limits.MaxBytes) },
+		set:   func(p *params, v float64) { p.limits.MaxBytes = int(v) },
+	},
+	"block.gas": {
+		valid: whole,
+		get:   func(p params) float64 { return float64(p.limits.MaxGas) },
+		set:   func(p *params, v float64) { p.limits.MaxGas = uint64(v) },
+	},
+	"fees.target": {
+		valid: whole,
+		get:   func(p params) float64 { return float64(p.fees.Target) },
+		set:   func(p *params, v float64) { p.fees.Target = int(v) },
+	},
+	"fees.denominator": {
+		valid: func(v float64) bool { return whole(v) && v > 0 },
+		get:   func(p params) float64 { return float64(p.fees.Denominator) },
+		set:   func(p *params, v float64) { p.fees.Denominator = int(v) },
+	},
+	"fees.minimum": {
+		valid: func(v float64) bool { return v >= 0 },
+		get:   func(p params) float64 { return p.fees.Minimum },
+		set:   func(p *params, v float64) { p.fees.Minimum = v },
+	},
+	"fees.gasPrice": {
+		valid: func(v float64) bool { return v >= 0 },
+		get:   func(p params) float64 { return p.fees.GasPrice },
+		set:   func(p *params, v float64) { p.fees.GasPrice = v },
+	},
+	"consensus.quorum": {
+		valid: func(v float64) bool { return whole(v) && v > 0 && v <= 100 },
+		get:   func(p params) float64 { return float64(p.quorum) },
+		set:   func(p *params, v float64) { p.quorum = int(v) },
+	},
+	"downtime.window": {
+		valid: whole,
+		get:   func(p params) float64 { return float64(p.downtime.Window) },
+		set:   func(p *params, v float64) { p.downtime.Window = uint64(v) },
+	},
+	"downtime.maxMissedProposals": {
+		valid: whole,
+		get:   func(p params) float64 { return float64(p.downtime.MaxMissedProposals) },
+		set:   func(p *params, v float64) { p.downtime.MaxMissedProposals = int(v) },
+	},
+	"downtime.maxMissedVotes": {
+		valid: whole,
+		get:   func(p params) float64 { return float64(p.downtime.MaxMissedVotes) },
+		set:   func(p *params, v float64) { p.downtime.MaxMissedVotes = int(v) },
+	},
+	"downtime.cooldown": {
+		valid: whole,
+		get:   func(p params) float64 { return float64(p.downtime.Cooldown) },
+		set:   func(p *params, v float64) { p.downtime.Cooldown = uint64(v) },
+	},
+	"punishment.jail": {
+		valid: whole,
+		get:   func(p params) float64 { return float64(p.punishment.Jail) },
+		set:   func(p *params, v float64) { p.punishment.Jail = uint64(v) },
+	},
+	"punishment.slash": {
+		valid: fraction,
+		get:   func(p params) float64 { return p.punishment.Slash },
+		set:   func(p *params, v float64) { p.punishment.Slash = v },
+	},
+	"punishment.unbonding": {
+		valid: whole,
+		get:   func(p params) float64 { return float64(p.punishment.Unbonding) },
+		set:   func(p *params, v float64) { p.punishment.Unbonding = uint64(v) },
+	},
+	"punishment.withhold": {
+		valid: fraction,
+		get:   func(p params) float64 { return p.punishment.Withhold },
+		set:   func(p *params, v float64) { p.punishment.Withhold = v },
+	},
+}
+
+// governed returns the parameters with the values that have been changed through governance.
+func (p params) governed(values map[string]float64) params {
+	for name, v := range values {
+		if param, ok := parameters[name]; ok {
+			param.set(&p, v)
+		}
+	}
+
+	return p
+}
+
+// values returns the value of every parameter that can be changed through governance.
+func (p params) values() map[string]float64 {
+	values := make(map[string]float64, len(parameters))
+
+	for name, param := range parameters {
+		values[name] = param.get(p)
+	}
+
+	return values
+}
+
+// proposal decodes the Proposal of the transaction.
+func (t Transaction) proposal() (Proposal, error) {
+	var p Proposal
+
+	if err := json.Unmarshal([]byte(t.Data), &p); err != nil {
+		return Proposal{}, fmt.Errorf("%w: invalid proposal", ErrInvalidTransaction)
+	}
+
+	return p, nil
+}
+
+// choice decodes the Choice of the transaction.
+func (t Transaction) choice() (Choice, error) {
+	var c Choice
+
+	if err := json.Unmarshal([]byte(t.Data), &c); err != nil {
+		return Choice{}, fmt.Errorf("%w: invalid choice", ErrInvalidTransaction)
+	}
+
+	return c, nil
+}
+
+// proposable checks whether the Propose transaction is valid; the sender should be a
+// validator with a registered consensus key, and the parameter should accept the value.
+// The proposal is activated after its voting period has passed.
+func (j *journal) proposable(transaction Transaction) (Proposal, error) {
+	if transaction.Amount != 0 {
+		return Proposal{}, fmt.Errorf("%w: amount should be zero", ErrInvalidTransaction)
+	}
+
+	p, err := transaction.proposal()
+	if err != nil {
+		return Proposal{}, err
+	}
+
+	param, ok := parameters[p.Parameter]
+	if !ok {
+		return Proposal{}, fmt.Errorf("%w: unknown parameter %s", ErrInvalidTransaction, p.Parameter)
+	}
+
+	if !param.valid(p.Value) {
+		return Proposal{}, fmt.Errorf("%w: invalid value for %s", ErrInvalidTransaction, p.Parameter)
+	}
+
+	if p.Activation <= j.height+votingPeriod {
+		return Proposal{}, fmt.Errorf("%w: activation should follow the voting period", ErrInvalidTransaction)
+	}
+
+	if _, ok = j.am.keyOf(transaction.Sender); !ok {
+		return Proposal{}, fmt.Errorf("%w: sender is not a validator", ErrInvalidTransaction)
+	}
+
+	return Proposal{
+		Parameter:  p.Parameter,
+		Value:      p.Value,
+		Activation: p.Activation,
+		Proposer:   transaction.Sender,
+		Deadline:   j.height + votingPeriod,
+		Votes:      make(map[string]bool),
+	}, nil
+}
+
+// propose applies a Propose transaction; the proposal is identified by the hash of the transaction.
+func (j *journal) propose(transaction Transaction) error {
+	p, err := j.proposable(transaction)
+	if err != nil {
+		return err
+	}
+
+	j.submit(util.HexEncode(transaction.Hash()), p)
+	j.modify(transaction.Sender, 0, true)
+
+	return nil
+}
+
+// castable checks whether the Ballot transaction is valid; the sender should be a
+// validator with a registered consensus key that votes once on the proposal, within
+// its voting period.
+func (j *journal) castable(transaction Transaction) (Choice, error) {
+	if transaction.Amount != 0 {
+		return Choice{}, fmt.Errorf("%w: amount should be zero", ErrInvalidTransaction)
+	}
+
+	c, err := transaction.choice()
+	if err != nil {
+		return Choice{}, err
+	}
+
+	p, ok := j.am.getProposal(c.Proposal)
+	if !ok {
+		return Choice{}, fmt.Errorf("%w: unknown proposal", ErrInvalidTransaction)
+	}
+
+	if j.height > p.Deadline {
+		return Choice{}, fmt.Errorf("%w: voting period has ended", ErrInvalidTransaction)
+	}
+
+	if _, ok = j.am.keyOf(transaction.Sender); !ok {
+		return Choice{}, fmt.Errorf("%w: sender is not a validator", ErrInvalidTransaction)
+	}
+
+	if _, ok = p.Votes[transaction.Sender]; ok {
+		return Choice{}, fmt.Errorf("%w: validator has already voted", ErrInvalidTransaction)
+	}
+
+	return c, nil
+}
+
+// ballot applies a Ballot transaction; the vote is weighed by stake once the proposal is tallied.
+func (j *journal) ballot(transaction Transaction) error {
+	c, err := j.castable(transaction)
+	if err != nil {
+		return err
+	}
+
+	j.cast(c.Proposal, transaction.Sender, c.Approve)
+	j.modify(transaction.Sender, 0, true)
+
+	return nil
+}
+
+// govern tallies the proposals that are activated at the height of the journal, in
+// order, and changes the parameters of the proposals that passed. The votes are
+// weighed by the given stakes.
+func (j *journal) govern(stakes map[string]Coin) {
+	// the stakes are summed as coins, as the sum of floats depends on the order of the map
+	total := ToCoin(0)
+
+	for _, stake := range stakes {
+		total = Coin{total.decimal.Add(stake.decimal)}
+	}
+
+	for _, id := range j.am.activated(j.height) {
+		p, _ := j.am.getProposal(id)
+
+		approved := ToCoin(0)
+
+		for v, approve := range p.Votes {
+			if approve {
+				approved = Coin{approved.decimal.Add(stakes[v].decimal)}
+			}
+		}
+
+		// more than two thirds of the stake approves
+		supermajority := approved.decimal.Mul(decimal.NewFromInt(3)).GreaterThan(total.decimal.Mul(decimal.NewFromInt(2)))
+
+		if total.decimal.IsPositive() && supermajority {
+			j.parameter(p.Parameter, p.Value)
+		}
+	}
+}
+
+// Proposals returns every proposal that has been submitted, by the hash of its transaction.
+func (b *Blockchain) Proposals() map[string]Proposal {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.am.getProposals()
+}
+
+// Parameters returns the current value of every parameter that can be changed through governance.
+func (b *Blockchain) Parameters() map[string]float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.governed().values()
+}
+
+// governed returns the consensus parameters of the next block, including the changes
+// made through governance so far.
+func (b *Blockchain) governed() params {
+	return b.params(b.Blocks[0]).governed(b.am.getParameters())
+}
+
+// sortedKeys returns the keys of the proposals in order.
+func sortedKeys(proposals map[string]Proposal) []string {
+	keys := make([]string, 0, len(proposals))
+
+	for k := range proposals {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,67 @@
+package blockchain
+
+import (
+	"backend/crypto"
+	"backend/util"
+)
+
+func (suite *ValidationTestSuite) TestGovernance() {
+	key, validator := newValidator(suite.T())
+	other, _ := newValidator(suite.T())
+	nonce := uint64(1)
+
+	// next creates an exchange transaction from genesis with the next nonce
+	next := func() Transaction {
+		nonce++
+
+		return suite.transaction(1, nonce-1)
+	}
+
+	t := next()
+	t.Receiver = ""
+	t.Type = Stake
+	t.Data = string(util.JSONEncode(Bond{Validator: validator, Key: util.HexEncode(crypto.EncodePublicKey(&key.PublicKey))}))
+	t = suite.sign(suite.priv, t)
+
+	suite.forge(t)
+
+	activation := suite.bc.Last().Height + votingPeriod + 2
+	proposal := suite.validatorTransaction(key, 0, Propose, Proposal{Parameter: "fees.target", Value: 10, Activation: activation})
+	rejected := suite.validatorTransaction(key, 1, Propose, Proposal{Parameter: "downtime.window", Value: 8, Activation: activation})
+
+	// only validators propose valid values, after the voting period
+	suite.ErrorIs(suite.bc.UpdateMempool(suite.validatorTransaction(other, 0, Propose, Proposal{Parameter: "fees.target", Value: 10, Activation: activation})), ErrInvalidTransaction)
+	suite.ErrorIs(suite.bc.UpdateMempool(suite.validatorTransaction(key, 0, Propose, Proposal{Parameter: "epoch", Value: 10, Activation: activation})), ErrInvalidTransaction)
+	suite.ErrorIs(suite.bc.UpdateMempool(suite.validatorTransaction(key, 0, Propose, Proposal{Parameter: "fees.target", Value: 0.5, Activation: activation})), ErrInvalidTransaction)
+	suite.ErrorIs(suite.bc.UpdateMempool(suite.validatorTransaction(key, 0, Propose, Proposal{Parameter: "fees.target", Value: 10, Activation: 2})), ErrInvalidTransaction)
+	suite.ErrorIs(suite.bc.UpdateMempool(suite.validatorTransaction(key, 0, Propose, Proposal{Parameter: "consensus.quorum", Value: 0, Activation: activation})), ErrInvalidTransaction)
+	suite.ErrorIs(suite.bc.UpdateMempool(suite.validatorTransaction(key, 0, Propose, Proposal{Parameter: "consensus.quorum", Value: 101, Activation: activation})), ErrInvalidTransaction)
+
+	suite.forge(proposal, rejected)
+
+	id := util.HexEncode(proposal.Hash())
+	approve := suite.validatorTransaction(key, 2, Ballot, Choice{Proposal: id, Approve: true})
+
+	suite.ErrorIs(suite.bc.UpdateMempool(suite.validatorTransaction(key, 2, Ballot, Choice{Proposal: "unknown", Approve: true})), ErrInvalidTransaction)
+	suite.forge(approve, suite.validatorTransaction(key, 3, Ballot, Choice{Proposal: util.HexEncode(rejected.Hash())}))
+	suite.True(suite.bc.Proposals()[id].Votes[validator])
+
+	// a validator votes only once
+	suite.ErrorIs(suite.bc.UpdateMempool(suite.validatorTransaction(key, 4, Ballot, Choice{Proposal: id})), ErrInvalidTransaction)
+
+	// the stake takes effect in the next epoch, and weighs the votes once the proposal is activated
+	for suite.bc.Last().Height < activation-1 {
+		suite.forge(next())
+	}
+
+	suite.Equal(0.0, suite.bc.Parameters()["fees.target"])
+	suite.Equal(float64(defaultQuorum), suite.bc.Parameters()["consensus.quorum"])
+
+	suite.forge(next())
+	suite.Equal(10.0, suite.bc.Parameters()["fees.target"])
+	suite.Equal(0.0, suite.bc.Parameters()["downtime.window"])
+
+	_, err := suite.bc.RevertBlock()
+	suite.NoError(err)
+	suite.Equal(0.0, suite.bc.Parameters()["fees.target"])
+}
@@ -0,0 +1,161 @@
+package blockchain
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"backend/util"
+)
+
+// Escrow holds the funds that are locked by a hash time-locked contract.
+// The funds are released to the receiver when the preimage of the hashlock is
+// revealed before the deadline, or refunded to the sender after the deadline.
+type Escrow struct {
+	Sender   string  `json:"sender"`
+	Receiver string  `json:"receiver"`
+	Amount   float64 `json:"amount"`
+	Hashlock string  `json:"hashlock"`
+	Deadline uint64  `json:"deadline"`
+}
+
+// Contract holds the parameters of a hash time-locked contract transaction.
+// A Lock sets the hashlock and deadline (block height); a Claim refers to the lock
+// and reveals the preimage; a Refund only refers to the lock.
+type Contract struct {
+	Hashlock string `json:"hashlock,omitempty"`
+	Deadline uint64 `json:"deadline,omitempty"`
+	Lock     string `json:"lock,omitempty"`
+	Preimage string `json:"preimage,omitempty"`
+}
+
+// Hashlock returns the hashlock of the given preimage.
+func Hashlock(preimage []byte) string {
+	h := sha256.Sum256(preimage)
+
+	return util.HexEncode(h[:])
+}
+
+// contract decodes the Contract of the transaction.
+func (t Transaction) contract() (Contract, error) {
+	var c Contract
+
+	if err := json.Unmarshal([]byte(t.Data), &c); err != nil {
+		return Contract{}, fmt.Errorf("%w: invalid contract", ErrInvalidTransaction)
+	}
+
+	return c, nil
+}
+
+// lockable checks whether the Lock transaction is valid at the height of the journal.
+func (j *journal) lockable(transaction Transaction) (Contract, error) {
+	c, err := transaction.contract()
+	if err != nil {
+		return Contract{}, err
+	}
+
+	if len(util.HexDecode(c.Hashlock)) != sha256.Size {
+		return Contract{}, fmt.Errorf("%w: invalid hashlock", ErrInvalidTransaction)
+	}
+
+	if j.height >= c.Deadline {
+		return Contract{}, fmt.Errorf("%w: deadline has passed", ErrInvalidTransaction)
+	}
+
+	if len(transaction.Receiver) == 0 || transaction.Receiver == transaction.Sender {
+		return Contract{}, fmt.Errorf("%w: invalid receiver", ErrInvalidTransaction)
+	}
+
+	return c, nil
+}
+
+// redeemable checks whether the Claim or Refund transaction is valid at the height of
+// the journal, and returns the id of the Escrow it releases.
+func (j *journal) redeemable(transaction Transaction) (string, Escrow, error) {
+	c, err := transaction.contract()
+	if err != nil {
+		return "", Escrow{}, err
+	}
+
+	if transaction.Amount != 0 {
+		return "", Escrow{}, fmt.Errorf("%w: amount should be zero", ErrInvalidTransaction)
+	}
+
+	escrow, err := j.am.getEscrow(c.Lock)
+	if err != nil {
+		return "", Escrow{}, fmt.Errorf("%w: unknown lock", ErrInvalidTransaction)
+	}
+
+	switch transaction.Type {
+	case Claim:
+		if transaction.Sender != escrow.Receiver {
+			return "", Escrow{}, fmt.Errorf("%w: only the receiver can claim", ErrInvalidTransaction)
+		}
+
+		if Hashlock(util.HexDecode(c.Preimage)) != escrow.Hashlock {
+			return "", Escrow{}, fmt.Errorf("%w: invalid preimage", ErrInvalidTransaction)
+		}
+
+		if j.height >= escrow.Deadline {
+			return "", Escrow{}, fmt.Errorf("%w: deadline has passed", ErrInvalidTransaction)
+		}
+	case Refund:
+		if transaction.Sender != escrow.Sender {
+			return "", Escrow{}, fmt.Errorf("%w: only the sender can refund", ErrInvalidTransaction)
+		}
+
+		if escrow.Deadline > j.height {
+			return "", Escrow{}, fmt.Errorf("%w: deadline has not passed", ErrInvalidTransaction)
+		}
+	case Stake, Unstake, Regular, Reward, Fee, Penalty, Exchange, Lock, Deploy, Call, Register, Renew, Transfer, Unjail, Commitment, Reveal, Propose, Ballot:
+		return "", Escrow{}, fmt.Errorf("%w: not a release", ErrInvalidTransaction)
+	}
+
+	return c.Lock, escrow, nil
+}
+
+// lock applies a Lock transaction; the funds of the sender are moved into a new
+// Escrow whose id is the hash of the transaction.
+func (j *journal) lock(transaction Transaction) error {
+	c, err := j.lockable(transaction)
+	if err != nil {
+		return err
+	}
+
+	if err = j.debit(transaction.Sender, transaction.Amount, true); err != nil {
+		return err
+	}
+
+	j.open(util.HexEncode(transaction.Hash()), Escrow{
+		Sender:   transaction.Sender,
+		Receiver: transaction.Receiver,
+		Amount:   transaction.Amount,
+		Hashlock: c.Hashlock,
+		Deadline: c.Deadline,
+	})
+
+	return nil
+}
+
+// release applies a Claim or Refund transaction; the funds of the Escrow are moved
+// to either the receiver or the sender of the lock.
+func (j *journal) release(transaction Transaction) error {
+	id, escrow, err := j.redeemable(transaction)
+	if err != nil {
+		return err
+	}
+
+	if _, err = j.settle(id); err != nil {
+		return err
+	}
+
+	j.modify(transaction.Sender, 0, true)
+
+	if transaction.Type == Claim {
+		j.credit(escrow.Receiver, escrow.Amount)
+	} else {
+		j.credit(escrow.Sender, escrow.Amount)
+	}
+
+	return nil
+}
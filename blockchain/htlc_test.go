@@ -0,0 +1,101 @@
+package blockchain
+
+import (
+	"testing"
+
+	"backend/util"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HTLCTestSuite struct {
+	suite.Suite
+	am   *accountModel
+	lock Transaction
+	id   string
+}
+
+func (suite *HTLCTestSuite) SetupTest() {
+	suite.am = newAccountModel()
+
+	_ = suite.am.add("alice", 100)
+	_ = suite.am.add("bob", 0)
+
+	suite.lock = Transaction{
+		Sender:   "alice",
+		Receiver: "bob",
+		Amount:   40,
+		Type:     Lock,
+		Data:     string(util.JSONEncode(Contract{Hashlock: Hashlock([]byte("secret")), Deadline: 10})),
+	}
+	suite.id = util.HexEncode(suite.lock.Hash())
+
+	suite.Require().NoError(newJournal(suite.am, 1).apply(suite.lock))
+}
+
+func TestHTLCTestSuite(t *testing.T) {
+	suite.Run(t, new(HTLCTestSuite))
+}
+
+// release creates a Claim or Refund transaction for the lock.
+func (suite *HTLCTestSuite) release(sender string, txType TxType, preimage string) Transaction {
+	account, _ := suite.am.get(sender)
+
+	return Transaction{
+		Sender:   sender,
+		Receiver: sender,
+		Nonce:    account.Transactions,
+		Type:     txType,
+		Data:     string(util.JSONEncode(Contract{Lock: suite.id, Preimage: util.HexEncode([]byte(preimage))})),
+	}
+}
+
+func (suite *HTLCTestSuite) TestLock() {
+	escrow, err := suite.am.getEscrow(suite.id)
+
+	suite.NoError(err)
+	suite.Equal(40.0, escrow.Amount)
+	suite.True(ToCoin(60).Equal(suite.am.accounts["alice"].Balance))
+}
+
+func (suite *HTLCTestSuite) TestLockPassedDeadline() {
+	t := suite.lock
+	t.Nonce = 1
+
+	suite.ErrorIs(newJournal(suite.am, 10).apply(t), ErrInvalidTransaction)
+}
+
+func (suite *HTLCTestSuite) TestClaim() {
+	suite.NoError(newJournal(suite.am, 9).apply(suite.release("bob", Claim, "secret")))
+	suite.True(ToCoin(40).Equal(suite.am.accounts["bob"].Balance))
+
+	_, err := suite.am.getEscrow(suite.id)
+
+	suite.Error(err)
+}
+
+func (suite *HTLCTestSuite) TestClaimInvalid() {
+	suite.ErrorIs(newJournal(suite.am, 5).apply(suite.release("bob", Claim, "guess")), ErrInvalidTransaction)
+	suite.ErrorIs(newJournal(suite.am, 5).apply(suite.release("alice", Claim, "secret")), ErrInvalidTransaction)
+	suite.ErrorIs(newJournal(suite.am, 10).apply(suite.release("bob", Claim, "secret")), ErrInvalidTransaction)
+}
+
+func (suite *HTLCTestSuite) TestRefund() {
+	suite.ErrorIs(newJournal(suite.am, 9).apply(suite.release("alice", Refund, "")), ErrInvalidTransaction)
+	suite.NoError(newJournal(suite.am, 10).apply(suite.release("alice", Refund, "")))
+	suite.True(ToCoin(100).Equal(suite.am.accounts["alice"].Balance))
+}
+
+func (suite *HTLCTestSuite) TestRollback() {
+	j := newJournal(suite.am, 5)
+
+	suite.NoError(j.apply(suite.release("bob", Claim, "secret")))
+
+	j.rollback()
+
+	_, err := suite.am.getEscrow(suite.id)
+
+	suite.NoError(err)
+	suite.True(ToCoin(0).Equal(suite.am.accounts["bob"].Balance))
+	suite.Equal(uint64(0), suite.am.accounts["bob"].Transactions)
+}
@@ -0,0 +1,126 @@
+package blockchain
+
+import (
+	"sync"
+)
+
+// Direction is the direction of a Transaction, as seen from an address.
+type Direction string
+
+const (
+	Sent     Direction = "sent"
+	Received Direction = "received"
+)
+
+// Record represents a singular transaction of an address within the indexer.
+type Record struct {
+	Transaction Transaction `json:"transaction"`
+	Direction   Direction   `json:"direction"`
+	Height      uint64      `json:"height"`
+	Position    int         `json:"position"`
+}
+
+// reference refers to a transaction within the blockchain.
+type reference struct {
+	direction Direction
+	height    uint64
+	position  int
+}
+
+// indexer maps every address to the transactions it has sent and received.
+type indexer struct {
+	sync.RWMutex
+	references map[string][]reference
+}
+
+// newIndexer creates a new indexer.
+func newIndexer() *indexer {
+	return &indexer{
+		references: make(map[string][]reference),
+	}
+}
+
+// clear clears the indexer.
+func (ix *indexer) clear() {
+	ix.Lock()
+	defer ix.Unlock()
+
+	ix.references = make(map[string][]reference)
+}
+
+// add indexes the transactions of the given blocks.
+func (ix *indexer) add(block ...Block) {
+	ix.Lock()
+	defer ix.Unlock()
+
+	for _, b := range block {
+		for i, t := range b.Transactions {
+			ix.insert(t.Sender, reference{direction: Sent, height: b.Height, position: i})
+
+			if t.Receiver != t.Sender {
+				ix.insert(t.Receiver, reference{direction: Received, height: b.Height, position: i})
+			}
+		}
+	}
+}
+
+// insert adds a reference to the given address.
+func (ix *indexer) insert(address string, ref reference) {
+	if len(address) == 0 {
+		return
+	}
+
+	ix.references[address] = append(ix.references[address], ref)
+}
+
+// remove removes the transactions of the given block from the index.
+// Only the last block of the blockchain can be removed.
+func (ix *indexer) remove(block Block) {
+	ix.Lock()
+	defer ix.Unlock()
+
+	for _, t := range block.Transactions {
+		for _, address := range []string{t.Sender, t.Receiver} {
+			refs := ix.references[address]
+
+			for len(refs) > 0 && refs[len(refs)-1].height == block.Height {
+				refs = refs[:len(refs)-1]
+			}
+
+			if len(refs) == 0 {
+				delete(ix.references, address)
+			} else {
+				ix.references[address] = refs
+			}
+		}
+	}
+}
+
+// get returns a page of references of the given address, starting with the most recent.
+// The total amount of references of the address is returned as well.
+func (ix *indexer) get(address string, page int, limit int) ([]reference, int) {
+	ix.RLock()
+	defer ix.RUnlock()
+
+	refs := ix.references[address]
+	total := len(refs)
+	start := page * limit
+
+	if 0 > start || start >= total || 0 >= limit {
+		return []reference{}, total
+	}
+
+	end := start + limit
+
+	if end > total {
+		end = total
+	}
+
+	result := make([]reference, 0, end-start)
+
+	for i := total - 1 - start; i > total-1-end; i-- {
+		result = append(result, refs[i])
+	}
+
+	return result, total
+}
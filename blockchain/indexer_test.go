@@ -0,0 +1,58 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexerPagination(t *testing.T) {
+	ix := newIndexer()
+
+	for i := 0; i < 5; i++ {
+		ix.add(Block{
+			Height:       uint64(i),
+			Transactions: []Transaction{{Sender: "alice", Receiver: "bob"}},
+		})
+	}
+
+	refs, total := ix.get("bob", 0, 2)
+
+	assert.Equal(t, 5, total)
+	assert.Equal(t, []reference{{Received, 4, 0}, {Received, 3, 0}}, refs)
+
+	refs, _ = ix.get("alice", 2, 2)
+
+	assert.Equal(t, []reference{{Sent, 0, 0}}, refs)
+
+	refs, _ = ix.get("alice", 3, 2)
+
+	assert.Empty(t, refs)
+}
+
+func TestIndexerRemove(t *testing.T) {
+	ix := newIndexer()
+
+	b1 := Block{Height: 1, Transactions: []Transaction{{Sender: "alice", Receiver: "bob"}}}
+	b2 := Block{Height: 2, Transactions: []Transaction{{Sender: "alice", Receiver: "carol"}}}
+
+	ix.add(b1, b2)
+	ix.remove(b2)
+
+	_, total := ix.get("alice", 0, 10)
+
+	assert.Equal(t, 1, total)
+	assert.NotContains(t, ix.references, "carol")
+}
+
+func (suite *ValidationTestSuite) TestHistory() {
+	suite.forge(suite.transaction(100, 1), suite.transaction(50, 2))
+
+	records, total := suite.bc.History(suite.wallet, 0, 10)
+
+	suite.Equal(2, total)
+	suite.Equal(uint64(1), records[0].Height)
+	suite.Equal(Received, records[0].Direction)
+	suite.Equal(1, records[0].Position)
+	suite.Equal(50.0, records[0].Transaction.Amount)
+}
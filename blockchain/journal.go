@@ -0,0 +1,495 @@
+package blockchain
+
+import (
+	"fmt"
+)
+
+// changeKind is the kind of a change.
+type changeKind int
+
+const (
+	accountChanged changeKind = iota
+	escrowOpened
+	escrowSettled
+	contractDeployed
+	storageWritten
+	receiptAdded
+	nameChanged
+	bondChanged
+	unbondingChanged
+	epochChanged
+	validatorJailed
+	validatorPunished
+	keyRegistered
+	supplyBurned
+	livenessChanged
+	secretCommitted
+	secretRevealed
+	proposalSubmitted
+	ballotCast
+	parameterChanged
+)
+
+// change represents a singular change to the accountModel. Depending on its kind,
+// the fields hold what is needed to revert the change.
+type change struct {
+	kind     changeKind
+	key      string
+	amount   float64
+	nonce    bool
+	created  bool
+	escrow   Escrow
+	name     Name
+	target   string
+	epoch    uint64
+	stakes   map[string]Coin
+	liveness Liveness
+	beacon   Beacon
+	slot     int64
+	value    int64
+}
+
+// journal records every change made to the accountModel, such that the changes
+// can either be committed or rolled back as a unit. Transactions are applied as
+// if they were in a block of the given height, under the rules and with the base fee
+// of that block; the forger is the validator of the block, which receives the tips.
+// A journal is certified when the certificate of its block reached the quorum.
+type journal struct {
+	am        *accountModel
+	height    uint64
+	rules     Rules
+	baseFee   Coin
+	gasPrice  float64
+	forger    string
+	unbonding uint64
+	certified bool
+	changes   []change
+}
+
+// newJournal creates a new journal on the given accountModel.
+func newJournal(am *accountModel, height uint64) *journal {
+	return &journal{
+		am:      am,
+		height:  height,
+		baseFee: ToCoin(0),
+		changes: make([]change, 0),
+	}
+}
+
+// under sets the rules that transactions are applied under.
+func (j *journal) under(rules Rules) *journal {
+	j.rules = rules
+
+	return j
+}
+
+// unbondingFor sets the amount of epochs after the current epoch in which unstaked funds
+// remain slashable; they are returned at once when it is zero.
+func (j *journal) unbondingFor(epochs uint64) *journal {
+	j.unbonding = epochs
+
+	return j
+}
+
+// charging sets the base fee that transactions pay, the price of the gas they use,
+// and the forger that receives their tips.
+func (j *journal) charging(baseFee Coin, gasPrice float64, forger string) *journal {
+	j.baseFee = baseFee
+	j.gasPrice = gasPrice
+	j.forger = forger
+
+	return j
+}
+
+// modify adds the amount to the balance of the given key, and increments the
+// transactions done by the account if nonce is set.
+// The account will be created if it does not exist.
+func (j *journal) modify(key string, amount float64, nonce bool) {
+	created := j.am.modify(key, amount, nonce)
+
+	j.changes = append(j.changes, change{kind: accountChanged, key: key, amount: amount, nonce: nonce, created: created})
+}
+
+// credit adds the amount to the balance of the given key.
+// The account will be created if it does not exist.
+func (j *journal) credit(key string, amount float64) {
+	j.modify(key, amount, false)
+}
+
+// debit subtracts the amount from the balance of the given key.
+// If nonce is set, the transactions done by the account will be incremented.
+func (j *journal) debit(key string, amount float64, nonce bool) error {
+	account, err := j.am.get(key)
+	if err != nil {
+		return fmt.Errorf("%w: unknown sender", ErrInvalidTransaction)
+	}
+
+	if amount > account.Balance.Float64() {
+		return fmt.Errorf("%w: insufficient funds", ErrInvalidTransaction)
+	}
+
+	j.modify(key, -amount, nonce)
+
+	return nil
+}
+
+// open opens a new Escrow with the given id.
+func (j *journal) open(id string, escrow Escrow) {
+	j.am.open(id, escrow)
+
+	j.changes = append(j.changes, change{kind: escrowOpened, key: id})
+}
+
+// settle settles the Escrow with the given id.
+func (j *journal) settle(id string) (Escrow, error) {
+	escrow, err := j.am.settle(id)
+	if err != nil {
+		return Escrow{}, err
+	}
+
+	j.changes = append(j.changes, change{kind: escrowSettled, key: id, escrow: escrow})
+
+	return escrow, nil
+}
+
+// deploy deploys a new SmartContract at the given address.
+func (j *journal) deploy(address string, contract *SmartContract) {
+	j.am.deploy(address, contract)
+
+	j.changes = append(j.changes, change{kind: contractDeployed, key: address})
+}
+
+// store writes a value to the storage of the SmartContract at the given address.
+func (j *journal) store(address string, slot int64, value int64) {
+	prev, existed := j.am.store(address, slot, value)
+
+	j.changes = append(j.changes, change{kind: storageWritten, key: address, slot: slot, value: prev, created: !existed})
+}
+
+// receipt adds the Receipt of the transaction with given hash.
+func (j *journal) receipt(hash string, receipt Receipt) {
+	j.am.addReceipt(hash, receipt)
+
+	j.changes = append(j.changes, change{kind: receiptAdded, key: hash})
+}
+
+// name sets the registration of the given name.
+func (j *journal) name(key string, name Name) {
+	prev, existed := j.am.setName(key, name)
+
+	j.changes = append(j.changes, change{kind: nameChanged, key: key, name: prev, created: !existed})
+}
+
+// bond adds the amount to the stake the delegator has bonded to the validator.
+func (j *journal) bond(delegator string, validator string, amount float64) {
+	j.am.bond(delegator, validator, amount)
+
+	j.changes = append(j.changes, change{kind: bondChanged, key: delegator, target: validator, amount: amount})
+}
+
+// unbond adds the amount to the stake that is being unbonded.
+func (j *journal) unbond(k unbondKey, amount float64) {
+	j.am.unbond(k, amount)
+
+	j.changes = append(j.changes, change{kind: unbondingChanged, key: k.delegator, target: k.validator, epoch: k.release, amount: amount})
+}
+
+// registerKey registers the consensus key of the validator.
+func (j *journal) registerKey(validator string, key string) {
+	j.am.registerKey(validator, key)
+
+	j.changes = append(j.changes, change{kind: keyRegistered, key: validator})
+}
+
+// burn takes the amount out of circulation.
+func (j *journal) burn(amount float64) {
+	j.am.burn(amount)
+
+	j.changes = append(j.changes, change{kind: supplyBurned, amount: amount})
+}
+
+// advance starts the given epoch, unless it has already been started. The validators
+// that withheld their secret within the current epoch are slashed by the given fraction,
+// after which the unbonded stakes of the epoch are released.
+func (j *journal) advance(epoch uint64, slash float64) {
+	if epoch <= j.am.currentEpoch() {
+		return
+	}
+
+	withholders := j.am.getBeacon().withholders()
+
+	prev, elected, beacon := j.am.advance(epoch)
+
+	j.changes = append(j.changes, change{kind: epochChanged, epoch: prev, stakes: elected, beacon: beacon})
+
+	for _, v := range withholders {
+		j.slash(v, slash)
+	}
+
+	j.releaseUnbonded(epoch)
+}
+
+// commitSecret records the commitment of the validator within the current epoch.
+func (j *journal) commitSecret(validator string, hash string) {
+	j.am.commitSecret(validator, hash)
+
+	j.changes = append(j.changes, change{kind: secretCommitted, key: validator})
+}
+
+// revealSecret records the secret the validator revealed within the current epoch.
+func (j *journal) revealSecret(validator string, secret string) {
+	j.am.revealSecret(validator, secret)
+
+	j.changes = append(j.changes, change{kind: secretRevealed, key: validator, target: secret})
+}
+
+// submit adds the proposal with the given id.
+func (j *journal) submit(id string, p Proposal) {
+	j.am.submit(id, p)
+
+	j.changes = append(j.changes, change{kind: proposalSubmitted, key: id})
+}
+
+// cast records the vote of the validator on the proposal with the given id.
+func (j *journal) cast(id string, validator string, approve bool) {
+	j.am.cast(id, validator, approve)
+
+	j.changes = append(j.changes, change{kind: ballotCast, key: id, target: validator})
+}
+
+// parameter changes the value of the parameter.
+func (j *journal) parameter(name string, value float64) {
+	prev, ok := j.am.setParameter(name, value)
+
+	j.changes = append(j.changes, change{kind: parameterChanged, key: name, amount: prev, created: !ok})
+}
+
+// jail jails the validator until the given epoch, unless it is already jailed until
+// a later epoch.
+func (j *journal) jail(validator string, until uint64) {
+	if prev, ok := j.am.jailedUntil(validator); ok && prev >= until {
+		return
+	}
+
+	prev, ok := j.am.jail(validator, until)
+
+	j.changes = append(j.changes, change{kind: validatorJailed, key: validator, epoch: prev, created: !ok})
+}
+
+// liveness sets the liveness of the validator.
+func (j *journal) liveness(validator string, l Liveness) {
+	prev, ok := j.am.setLiveness(validator, l)
+
+	j.changes = append(j.changes, change{kind: livenessChanged, key: validator, liveness: prev, created: !ok})
+}
+
+// punished records that the validator has been punished for the proposal with the given key.
+func (j *journal) punished(key string) {
+	j.am.punish(key)
+
+	j.changes = append(j.changes, change{kind: validatorPunished, key: key})
+}
+
+// rollbackTo reverts all changes made after the given amount of changes.
+func (j *journal) rollbackTo(n int) {
+	for i := len(j.changes) - 1; i >= n; i-- {
+		j.am.revert(j.changes[i])
+	}
+
+	j.changes = j.changes[:n]
+}
+
+// verify checks whether the transaction is valid against the current state;
+// the nonce of the transaction should match the amount of transactions done by
+// the sender. A sender without account has done zero transactions.
+func (j *journal) verify(transaction Transaction) error {
+	if 0 > transaction.Amount {
+		return fmt.Errorf("%w: negative amount", ErrInvalidTransaction)
+	}
+
+	var nonce uint64
+
+	if tx, err := j.am.get(transaction.Sender); err == nil {
+		nonce = tx.Transactions
+	}
+
+	if nonce != transaction.Nonce {
+		return fmt.Errorf("%w: invalid nonce", ErrInvalidTransaction)
+	}
+
+	return nil
+}
+
+// apply validates the given transaction against the current state and applies it,
+// after its fees have been paid. No changes are made when the transaction is invalid.
+func (j *journal) apply(transaction Transaction) error {
+	if err := j.verify(transaction); err != nil {
+		return err
+	}
+
+	if err := j.allowed(transaction); err != nil {
+		return err
+	}
+
+	n := len(j.changes)
+
+	if err := j.pay(transaction); err != nil {
+		return err
+	}
+
+	if err := j.execute(transaction); err != nil {
+		j.rollbackTo(n)
+
+		return err
+	}
+
+	return nil
+}
+
+// execute applies the given transaction according to its type.
+func (j *journal) execute(transaction Transaction) error {
+	switch transaction.Type {
+	case Stake, Unstake:
+		return j.stake(transaction)
+	case Lock:
+		return j.lock(transaction)
+	case Claim, Refund:
+		return j.release(transaction)
+	case Deploy, Call:
+		return j.invoke(transaction)
+	case Register, Renew, Transfer:
+		return j.register(transaction)
+	case Unjail:
+		return j.unjail(transaction)
+	case Commitment:
+		return j.commitment(transaction)
+	case Reveal:
+		return j.reveal(transaction)
+	case Propose:
+		return j.propose(transaction)
+	case Ballot:
+		return j.ballot(transaction)
+	case Regular, Reward, Fee, Penalty, Exchange:
+	}
+
+	if err := j.debit(transaction.Sender, transaction.Amount, true); err != nil {
+		return err
+	}
+
+	j.credit(transaction.Receiver, transaction.Amount)
+
+	return nil
+}
+
+// reserve validates the given transaction against the current state, and only
+// debits the sender, including its fees. It is used for transactions that are not
+// yet in a block. No changes are made when the transaction is invalid.
+func (j *journal) reserve(transaction Transaction) error {
+	if err := j.verify(transaction); err != nil {
+		return err
+	}
+
+	if err := j.allowed(transaction); err != nil {
+		return err
+	}
+
+	n := len(j.changes)
+
+	if err := j.pay(transaction); err != nil {
+		return err
+	}
+
+	if err := j.hold(transaction); err != nil {
+		j.rollbackTo(n)
+
+		return err
+	}
+
+	return nil
+}
+
+// hold validates the given transaction according to its type, and debits the sender.
+func (j *journal) hold(transaction Transaction) error {
+	switch transaction.Type {
+	case Claim, Refund:
+		if _, _, err := j.redeemable(transaction); err != nil {
+			return err
+		}
+
+		j.modify(transaction.Sender, 0, true)
+
+		return nil
+	case Unstake:
+		if _, err := j.bondable(transaction); err != nil {
+			return err
+		}
+
+		j.modify(transaction.Sender, 0, true)
+
+		return nil
+	case Unjail:
+		if err := j.unjailable(transaction); err != nil {
+			return err
+		}
+
+		j.modify(transaction.Sender, 0, true)
+
+		return nil
+	case Propose:
+		if _, err := j.proposable(transaction); err != nil {
+			return err
+		}
+
+		j.modify(transaction.Sender, 0, true)
+
+		return nil
+	case Ballot:
+		if _, err := j.castable(transaction); err != nil {
+			return err
+		}
+
+		j.modify(transaction.Sender, 0, true)
+
+		return nil
+	case Commitment:
+		return j.commitment(transaction)
+	case Reveal:
+		return j.reveal(transaction)
+	case Stake:
+		if _, err := j.bondable(transaction); err != nil {
+			return err
+		}
+	case Lock:
+		if _, err := j.lockable(transaction); err != nil {
+			return err
+		}
+	case Deploy, Call:
+		i, err := j.invocable(transaction)
+		if err != nil {
+			return err
+		}
+
+		// a call reserves the gas it might use
+		if transaction.Type == Call {
+			return j.debit(transaction.Sender, ToCoin(transaction.Amount).Add(j.gasCost(i.Gas)).Float64(), true)
+		}
+	case Register, Renew, Transfer:
+		if _, _, err := j.registrable(transaction); err != nil {
+			return err
+		}
+	case Regular, Reward, Fee, Penalty, Exchange:
+	}
+
+	return j.debit(transaction.Sender, transaction.Amount, true)
+}
+
+// commit makes all changes permanent; the changes can no longer be rolled back.
+func (j *journal) commit() {
+	j.changes = j.changes[:0]
+}
+
+// rollback reverts all changes in reverse order, leaving the accountModel
+// exactly as it was before the changes were made.
+func (j *journal) rollback() {
+	j.rollbackTo(0)
+}
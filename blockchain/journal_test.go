@@ -0,0 +1,115 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalRollback(t *testing.T) {
+	am := newAccountModel()
+
+	_ = am.add("sender", 100)
+
+	j := newJournal(am, 1)
+
+	assert.NoError(t, j.apply(Transaction{Sender: "sender", Receiver: "receiver", Amount: 40.25}))
+	assert.NoError(t, j.apply(Transaction{Sender: "sender", Receiver: "receiver", Amount: 10, Nonce: 1}))
+
+	j.rollback()
+
+	assert.True(t, ToCoin(100).Equal(am.accounts["sender"].Balance))
+	assert.Equal(t, uint64(0), am.accounts["sender"].Transactions)
+	assert.False(t, am.exists("receiver"))
+}
+
+func TestJournalCommit(t *testing.T) {
+	am := newAccountModel()
+
+	_ = am.add("sender", 100)
+
+	j := newJournal(am, 1)
+
+	assert.NoError(t, j.apply(Transaction{Sender: "sender", Receiver: "receiver", Amount: 40}))
+
+	j.commit()
+	j.rollback()
+
+	assert.True(t, ToCoin(60).Equal(am.accounts["sender"].Balance))
+	assert.True(t, ToCoin(40).Equal(am.accounts["receiver"].Balance))
+}
+
+func TestJournalInvalidTransaction(t *testing.T) {
+	am := newAccountModel()
+
+	_ = am.add("sender", 100)
+
+	j := newJournal(am, 1)
+
+	assert.ErrorIs(t, j.apply(Transaction{Sender: "sender", Receiver: "receiver", Amount: 10, Nonce: 1}), ErrInvalidTransaction)
+	assert.ErrorIs(t, j.apply(Transaction{Sender: "sender", Receiver: "receiver", Amount: 101}), ErrInvalidTransaction)
+	assert.Empty(t, j.changes)
+}
+
+func (suite *ValidationTestSuite) TestRejectedBlockKeepsBalances() {
+	suite.Require().NoError(suite.bc.UpdateMempool(suite.transaction(100, 1)))
+
+	before := *suite.bc.am.accounts[suite.genesis]
+
+	block := suite.block(suite.key, suite.bc.Last(), 1, suite.transaction(10, 1), suite.transaction(10, 3))
+
+	suite.Error(suite.bc.AddBlock(block))
+	suite.True(before.Balance.Equal(suite.bc.am.accounts[suite.genesis].Balance))
+	suite.Equal(before.Transactions, suite.bc.am.accounts[suite.genesis].Transactions)
+	suite.False(suite.bc.am.exists(suite.wallet))
+}
+
+func (suite *ValidationTestSuite) TestDropTransaction() {
+	before := *suite.bc.am.accounts[suite.genesis]
+	t := suite.transaction(100, 1)
+
+	suite.Require().NoError(suite.bc.UpdateMempool(t))
+	suite.Require().NoError(suite.bc.DropTransaction(t))
+
+	suite.True(before.Balance.Equal(suite.bc.am.accounts[suite.genesis].Balance))
+	suite.Equal(before.Transactions, suite.bc.am.accounts[suite.genesis].Transactions)
+}
+
+func (suite *ValidationTestSuite) TestDropTransactionNonce() {
+	before := *suite.bc.am.accounts[suite.genesis]
+	first, second := suite.transaction(100, 1), suite.transaction(50, 2)
+
+	suite.Require().NoError(suite.bc.UpdateMempool(first))
+	suite.Require().NoError(suite.bc.UpdateMempool(second))
+	suite.Require().NoError(suite.bc.DropTransaction(first))
+
+	// the transaction that depends on the nonce of the dropped one is dropped too
+	suite.False(suite.bc.mp.exists(second.String()))
+	suite.True(before.Balance.Equal(suite.bc.am.accounts[suite.genesis].Balance))
+	suite.Equal(before.Transactions, suite.bc.am.accounts[suite.genesis].Transactions)
+
+	// the nonce can be used again
+	suite.NoError(suite.bc.UpdateMempool(suite.transaction(100, 1)))
+}
+
+func (suite *ValidationTestSuite) TestRevertBlock() {
+	before := *suite.bc.am.accounts[suite.genesis]
+
+	block := suite.forge(suite.transaction(100, 1))
+
+	reverted, err := suite.bc.RevertBlock()
+
+	suite.NoError(err)
+	suite.Equal(block, reverted)
+	suite.Len(suite.bc.Blocks, 1)
+	suite.False(suite.bc.am.exists(suite.wallet))
+
+	// the transaction is returned to the memory pool
+	suite.True(before.Balance.Sub(100).Equal(suite.bc.am.accounts[suite.genesis].Balance))
+	suite.Require().NoError(suite.bc.DropTransaction(block.Transactions[0]))
+	suite.True(before.Balance.Equal(suite.bc.am.accounts[suite.genesis].Balance))
+
+	_, err = suite.bc.RevertBlock()
+
+	suite.Error(err)
+}
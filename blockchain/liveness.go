@@ -0,0 +1,194 @@
+package blockchain
+
+import (
+	"fmt"
+)
+
+// Downtime holds the parameters of liveness tracking. The proposals and votes a
+// validator missed are counted over a sliding window of the last Window blocks; a
+// validator that missed MaxMissedProposals proposals or MaxMissedVotes votes within
+// the window is jailed. It can unjail itself after Cooldown epochs through an Unjail
+// transaction. Liveness is not tracked when the window is zero.
+type Downtime struct {
+	Window             uint64 `json:"window"`
+	MaxMissedProposals int    `json:"maxMissedProposals"`
+	MaxMissedVotes     int    `json:"maxMissedVotes"`
+	Cooldown           uint64 `json:"cooldown"`
+}
+
+// Liveness holds the heights of the blocks within the window for which a validator
+// missed its proposal or its vote. A jailed validator is excluded from the validator
+// set until it unjails itself, which is allowed from the epoch of release onwards.
+type Liveness struct {
+	MissedProposals []uint64 `json:"missedProposals"`
+	MissedVotes     []uint64 `json:"missedVotes"`
+	Jailed          bool     `json:"jailed"`
+	Release         uint64   `json:"release"`
+}
+
+// within returns the heights that are still within the window that ends at the given height.
+func within(heights []uint64, height uint64, window uint64) []uint64 {
+	w := make([]uint64, 0, len(heights)+1)
+
+	for _, h := range heights {
+		if h+window > height {
+			w = append(w, h)
+		}
+	}
+
+	return w
+}
+
+// record returns the liveness after the block of the given height, in which the
+// validator might have missed its proposal or its vote; misses that fall out of the
+// window are forgotten. False is returned when the liveness did not change.
+func (l Liveness) record(height uint64, window uint64, proposal bool, vote bool) (Liveness, bool) {
+	next := l
+	next.MissedProposals = within(l.MissedProposals, height, window)
+	next.MissedVotes = within(l.MissedVotes, height, window)
+
+	if proposal {
+		next.MissedProposals = append(next.MissedProposals, height)
+	}
+
+	if vote {
+		next.MissedVotes = append(next.MissedVotes, height)
+	}
+
+	changed := len(next.MissedProposals) != len(l.MissedProposals) || len(next.MissedVotes) != len(l.MissedVotes)
+
+	return next, changed || proposal || vote
+}
+
+// exceeds checks whether the liveness exceeds the maximum misses of the downtime.
+func (l Liveness) exceeds(downtime Downtime) bool {
+	if downtime.MaxMissedProposals > 0 && len(l.MissedProposals) >= downtime.MaxMissedProposals {
+		return true
+	}
+
+	return downtime.MaxMissedVotes > 0 && len(l.MissedVotes) >= downtime.MaxMissedVotes
+}
+
+// trackLiveness records the proposals and votes the validators missed in the block
+// through the given journal, and jails the validators that missed too many. The
+// proposers of earlier ranks in the slot of the block, as elected by the seed, missed
+// their proposal; the validators of the last block whose vote is not in the
+// certificate of its parent the block carries, missed their vote. The parent should
+// hold every vote of the certificate of the last block, such that a proposer cannot
+// leave out the votes of honest validators to have them jailed.
+func trackLiveness(j *journal, p params, last Block, block Block, seed []byte, stakes map[string]Coin, voters map[string]Coin) error {
+	if p.downtime.Window == 0 {
+		return nil
+	}
+
+	proposals := make(map[string]bool)
+	votes := make(map[string]bool)
+
+	if proposers, err := p.elect(seed, block.Slot, stakes); err == nil {
+		for r := 0; r < int(block.Rank) && r < len(proposers); r++ {
+			proposals[proposers[r]] = true
+		}
+	}
+
+	// the genesis block is not voted on
+	if last.Height > 0 {
+		if err := last.verifyVotes(block.Parent); err != nil {
+			return err
+		}
+
+		parent := make(map[string]bool, len(block.Parent))
+
+		for _, v := range block.Parent {
+			parent[v.Validator] = true
+		}
+
+		for _, v := range last.Certificate {
+			if !parent[v.Validator] {
+				return fmt.Errorf("%w, %s", ErrInvalidBlock, "parent omits votes of the certificate")
+			}
+		}
+
+		for v := range voters {
+			votes[v] = true
+		}
+
+		for _, v := range block.Parent {
+			delete(votes, v.Validator)
+		}
+	}
+
+	validators := copyStakes(stakes)
+
+	for v, stake := range voters {
+		validators[v] = stake
+	}
+
+	for v := range validators {
+		j.track(v, proposals[v], votes[v], p.downtime)
+	}
+
+	return nil
+}
+
+// track records whether the validator missed its proposal or its vote in the block
+// of the journal, and jails the validator when it missed too many within the window.
+func (j *journal) track(validator string, proposal bool, vote bool, downtime Downtime) {
+	l, _ := j.am.livenessOf(validator)
+
+	next, changed := l.record(j.height, downtime.Window, proposal, vote)
+	if !changed {
+		return
+	}
+
+	if !next.Jailed && next.exceeds(downtime) {
+		next.Jailed = true
+		next.Release = j.am.currentEpoch() + downtime.Cooldown
+	}
+
+	j.liveness(validator, next)
+}
+
+// unjailable checks whether the Unjail transaction is valid; the sender should be a
+// validator that has been jailed for its downtime, and whose cooldown has passed.
+func (j *journal) unjailable(transaction Transaction) error {
+	if transaction.Amount != 0 {
+		return fmt.Errorf("%w: amount should be zero", ErrInvalidTransaction)
+	}
+
+	l, ok := j.am.livenessOf(transaction.Sender)
+	if !ok || !l.Jailed {
+		return fmt.Errorf("%w: validator is not jailed", ErrInvalidTransaction)
+	}
+
+	if j.am.currentEpoch() < l.Release {
+		return fmt.Errorf("%w: validator is cooling down", ErrInvalidTransaction)
+	}
+
+	return nil
+}
+
+// unjail applies an Unjail transaction; the validator rejoins the validator set
+// with a clean record.
+func (j *journal) unjail(transaction Transaction) error {
+	if err := j.unjailable(transaction); err != nil {
+		return err
+	}
+
+	j.liveness(transaction.Sender, Liveness{MissedProposals: []uint64{}, MissedVotes: []uint64{}})
+	j.modify(transaction.Sender, 0, true)
+
+	return nil
+}
+
+// SetDowntime sets the parameters of liveness tracking.
+func (b *Blockchain) SetDowntime(downtime Downtime) {
+	b.downtime = downtime
+}
+
+// Liveness returns the liveness of every validator that has been tracked.
+func (b *Blockchain) Liveness() map[string]Liveness {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.am.livenesses()
+}
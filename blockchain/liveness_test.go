@@ -0,0 +1,121 @@
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"backend/crypto"
+	"backend/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *ValidationTestSuite) TestLiveness() {
+	key, validator := newValidator(suite.T())
+	nonce := uint64(1)
+
+	// next creates an exchange transaction from genesis with the next nonce
+	next := func() Transaction {
+		nonce++
+
+		return suite.transaction(1, nonce-1)
+	}
+
+	t := next()
+	t.Receiver = ""
+	t.Type = Stake
+	t.Data = string(util.JSONEncode(Bond{Validator: validator, Key: util.HexEncode(crypto.EncodePublicKey(&key.PublicKey))}))
+	t = suite.sign(suite.priv, t)
+
+	suite.forge(t)
+	suite.bc.SetDowntime(Downtime{Window: 4, MaxMissedVotes: 2, Cooldown: 1})
+
+	// the stake takes effect in the next epoch, after which the validator should vote
+	for suite.bc.Last().Slot < defaultEpoch {
+		suite.forge(next())
+	}
+
+	suite.Empty(suite.bc.Liveness())
+
+	suite.forge(next())
+	suite.Len(suite.bc.Liveness()[validator].MissedVotes, 1)
+
+	// the vote on the last block is carried by the next block
+	last := suite.bc.Last()
+	vote := NewVote(validator, last, true)
+	suite.Require().NoError(vote.Sign(key))
+
+	block := suite.block(suite.key, last, last.Slot+1, next())
+	block.Parent = []Vote{vote}
+	suite.Require().NoError(block.Sign(suite.key))
+
+	invalid := block
+	invalid.Parent = []Vote{NewVote(validator, block, true)}
+	suite.Require().NoError(invalid.Sign(suite.key))
+
+	suite.ErrorIs(suite.bc.ValidateBlock(invalid), ErrInvalidBlock)
+
+	// the parent cannot leave out the votes of the certificate of the last block
+	suite.bc.Blocks[len(suite.bc.Blocks)-1].Certificate = []Vote{vote}
+
+	omitting := block
+	omitting.Parent = nil
+	suite.Require().NoError(omitting.Sign(suite.key))
+
+	suite.ErrorIs(suite.bc.ValidateBlock(omitting), ErrInvalidBlock)
+	suite.NoError(suite.bc.AddBlock(block))
+	suite.Len(suite.bc.Liveness()[validator].MissedVotes, 1)
+
+	// the validator is jailed once it missed two votes within the window
+	suite.forge(next())
+
+	liveness := suite.bc.Liveness()[validator]
+
+	suite.True(liveness.Jailed)
+	suite.Equal(uint64(2), liveness.Release)
+	suite.Empty(suite.bc.Validators(suite.bc.Last().Slot + 1))
+
+	unjail := suite.sign(key, Transaction{
+		Sender:    validator,
+		Timestamp: time.Now().Unix(),
+		Type:      Unjail,
+	})
+
+	// the validator cannot unjail itself during its cooldown
+	suite.ErrorIs(suite.bc.UpdateMempool(unjail), ErrInvalidTransaction)
+
+	_, err := suite.bc.RevertBlock()
+	suite.NoError(err)
+	suite.False(suite.bc.Liveness()[validator].Jailed)
+
+	suite.forge()
+	suite.True(suite.bc.Liveness()[validator].Jailed)
+
+	for suite.bc.Last().Slot < 2*defaultEpoch {
+		suite.forge(next())
+	}
+
+	suite.forge(unjail)
+	suite.Equal(Liveness{MissedProposals: []uint64{}, MissedVotes: []uint64{}}, suite.bc.Liveness()[validator])
+	suite.Contains(suite.bc.Validators(suite.bc.Last().Slot+1), validator)
+}
+
+func TestLivenessWindow(t *testing.T) {
+	downtime := Downtime{Window: 3, MaxMissedProposals: 2}
+
+	l, changed := Liveness{}.record(1, downtime.Window, true, false)
+	assert.True(t, changed)
+	assert.False(t, l.exceeds(downtime))
+
+	// misses that fall out of the window are forgotten
+	l, changed = l.record(4, downtime.Window, true, true)
+	assert.True(t, changed)
+	assert.Equal(t, []uint64{4}, l.MissedProposals)
+	assert.Equal(t, []uint64{4}, l.MissedVotes)
+
+	_, changed = l.record(5, downtime.Window, false, false)
+	assert.False(t, changed)
+
+	l, _ = l.record(6, downtime.Window, true, false)
+	assert.True(t, l.exceeds(downtime))
+}
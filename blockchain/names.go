@@ -0,0 +1,125 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"backend/crypto"
+)
+
+const (
+	// NameFee is the fee that is burned when a name is registered or renewed.
+	NameFee = 1.0
+	// namePeriod is the amount of blocks a name is registered for.
+	namePeriod uint64 = 100000
+)
+
+// validName matches names of 3 up to 32 lowercase letters, digits and hyphens.
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,30}[a-z0-9]$`)
+
+// Name represents a registered name, which resolves to the account of its owner
+// until the registration expires at the given block height.
+type Name struct {
+	Owner  string `json:"owner"`
+	Expiry uint64 `json:"expiry"`
+}
+
+// Registration holds the parameters of a Register, Renew or Transfer transaction.
+// A Transfer hands the name over to the receiver of the transaction.
+type Registration struct {
+	Name string `json:"name"`
+}
+
+// active checks whether the name is still registered at the given height.
+func (n Name) active(height uint64) bool {
+	return height < n.Expiry
+}
+
+// registration decodes the Registration of the transaction.
+func (t Transaction) registration() (Registration, error) {
+	var r Registration
+
+	if err := json.Unmarshal([]byte(t.Data), &r); err != nil {
+		return Registration{}, fmt.Errorf("%w: invalid registration", ErrInvalidTransaction)
+	}
+
+	return r, nil
+}
+
+// ValidateName checks whether the name can be registered. Names that are valid
+// addresses are rejected, such that a name never shadows an account.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) || crypto.ValidateAddress(name) == nil {
+		return fmt.Errorf("%w: invalid name", ErrInvalidTransaction)
+	}
+
+	return nil
+}
+
+// registrable checks whether the Register, Renew or Transfer transaction is valid
+// at the height of the journal, and returns the name with its updated registration.
+func (j *journal) registrable(transaction Transaction) (string, Name, error) {
+	r, err := transaction.registration()
+	if err != nil {
+		return "", Name{}, err
+	}
+
+	if err = ValidateName(r.Name); err != nil {
+		return "", Name{}, err
+	}
+
+	name, err := j.am.getName(r.Name)
+	registered := err == nil && name.active(j.height)
+
+	switch transaction.Type {
+	case Register:
+		if registered {
+			return "", Name{}, fmt.Errorf("%w: name is already registered", ErrInvalidTransaction)
+		}
+
+		name = Name{Owner: transaction.Sender, Expiry: j.height + namePeriod}
+	case Renew:
+		if !registered || name.Owner != transaction.Sender {
+			return "", Name{}, fmt.Errorf("%w: only the owner can renew", ErrInvalidTransaction)
+		}
+
+		name.Expiry += namePeriod
+	case Transfer:
+		if !registered || name.Owner != transaction.Sender {
+			return "", Name{}, fmt.Errorf("%w: only the owner can transfer", ErrInvalidTransaction)
+		}
+
+		name.Owner = transaction.Receiver
+	case Stake, Unstake, Regular, Reward, Fee, Penalty, Exchange, Lock, Claim, Refund, Deploy, Call, Unjail, Commitment, Reveal, Propose, Ballot:
+		return "", Name{}, fmt.Errorf("%w: not a registration", ErrInvalidTransaction)
+	}
+
+	fee := 0.0
+	if transaction.Type != Transfer {
+		fee = NameFee
+	}
+
+	if transaction.Amount != fee {
+		return "", Name{}, fmt.Errorf("%w: amount should be %.2f", ErrInvalidTransaction, fee)
+	}
+
+	return r.Name, name, nil
+}
+
+// register applies a Register, Renew or Transfer transaction; the fee paid by the
+// sender is burned.
+func (j *journal) register(transaction Transaction) error {
+	key, name, err := j.registrable(transaction)
+	if err != nil {
+		return err
+	}
+
+	if err = j.debit(transaction.Sender, transaction.Amount, true); err != nil {
+		return err
+	}
+
+	j.name(key, name)
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package blockchain
+
+import (
+	"testing"
+
+	"backend/util"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NamesTestSuite struct {
+	suite.Suite
+	am *accountModel
+}
+
+func (suite *NamesTestSuite) SetupTest() {
+	suite.am = newAccountModel()
+
+	_ = suite.am.add("alice", 10)
+	_ = suite.am.add("bob", 10)
+
+	suite.Require().NoError(newJournal(suite.am, 1).apply(suite.registration("alice", "", Register, "alice")))
+}
+
+func TestNamesTestSuite(t *testing.T) {
+	suite.Run(t, new(NamesTestSuite))
+}
+
+// registration creates a Register, Renew or Transfer transaction for the name.
+func (suite *NamesTestSuite) registration(sender string, receiver string, txType TxType, name string) Transaction {
+	account, _ := suite.am.get(sender)
+
+	amount := NameFee
+	if txType == Transfer {
+		amount = 0
+	}
+
+	return Transaction{
+		Sender:   sender,
+		Receiver: receiver,
+		Amount:   amount,
+		Nonce:    account.Transactions,
+		Type:     txType,
+		Data:     string(util.JSONEncode(Registration{Name: name})),
+	}
+}
+
+func (suite *NamesTestSuite) TestRegister() {
+	name, err := suite.am.getName("alice")
+
+	suite.NoError(err)
+	suite.Equal(Name{Owner: "alice", Expiry: 1 + namePeriod}, name)
+	suite.True(ToCoin(10 - NameFee).Equal(suite.am.accounts["alice"].Balance))
+}
+
+func (suite *NamesTestSuite) TestRegisterInvalid() {
+	suite.ErrorIs(newJournal(suite.am, 2).apply(suite.registration("bob", "", Register, "alice")), ErrInvalidTransaction)
+	suite.ErrorIs(newJournal(suite.am, 2).apply(suite.registration("bob", "", Register, "Bob")), ErrInvalidTransaction)
+	suite.ErrorIs(newJournal(suite.am, 2).apply(suite.registration("bob", "", Register, "b")), ErrInvalidTransaction)
+
+	t := suite.registration("bob", "", Register, "bob")
+	t.Amount = 0
+
+	suite.ErrorIs(newJournal(suite.am, 2).apply(t), ErrInvalidTransaction)
+}
+
+func (suite *NamesTestSuite) TestRegisterExpired() {
+	suite.NoError(newJournal(suite.am, 1+namePeriod).apply(suite.registration("bob", "", Register, "alice")))
+
+	name, _ := suite.am.getName("alice")
+
+	suite.Equal("bob", name.Owner)
+}
+
+func (suite *NamesTestSuite) TestRenew() {
+	suite.ErrorIs(newJournal(suite.am, 2).apply(suite.registration("bob", "", Renew, "alice")), ErrInvalidTransaction)
+	suite.NoError(newJournal(suite.am, 2).apply(suite.registration("alice", "", Renew, "alice")))
+
+	name, _ := suite.am.getName("alice")
+
+	suite.Equal(1+2*namePeriod, name.Expiry)
+}
+
+func (suite *NamesTestSuite) TestTransfer() {
+	suite.ErrorIs(newJournal(suite.am, 2).apply(suite.registration("bob", "bob", Transfer, "alice")), ErrInvalidTransaction)
+	suite.NoError(newJournal(suite.am, 2).apply(suite.registration("alice", "bob", Transfer, "alice")))
+
+	name, _ := suite.am.getName("alice")
+
+	suite.Equal("bob", name.Owner)
+	suite.True(ToCoin(10 - NameFee).Equal(suite.am.accounts["alice"].Balance))
+}
+
+func (suite *NamesTestSuite) TestRollback() {
+	j := newJournal(suite.am, 2)
+
+	suite.NoError(j.apply(suite.registration("bob", "", Register, "bob")))
+	suite.NoError(j.apply(suite.registration("alice", "bob", Transfer, "alice")))
+
+	j.rollback()
+
+	_, err := suite.am.getName("bob")
+	name, _ := suite.am.getName("alice")
+
+	suite.Error(err)
+	suite.Equal("alice", name.Owner)
+}
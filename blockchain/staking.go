@@ -0,0 +1,138 @@
+package blockchain
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+
+	"backend/crypto"
+	"backend/util"
+)
+
+// Bond holds the parameters of a Stake or Unstake transaction; the validator is the
+// address of the consensus key of the validator the stake of the sender is bonded to.
+// The key is the encoded consensus key itself, which is registered on-chain along
+// with the first stake that is bonded to the validator.
+type Bond struct {
+	Validator string `json:"validator"`
+	Key       string `json:"key,omitempty"`
+}
+
+// bondKey identifies the stake a delegator has bonded to a validator.
+type bondKey struct {
+	delegator string
+	validator string
+}
+
+// unbondKey identifies the stake a delegator has unbonded from a validator, which is
+// returned to the delegator once the epoch of its release starts.
+type unbondKey struct {
+	delegator string
+	validator string
+	release   uint64
+}
+
+// bond decodes the Bond of the transaction.
+func (t Transaction) bond() (Bond, error) {
+	var b Bond
+
+	if err := json.Unmarshal([]byte(t.Data), &b); err != nil {
+		return Bond{}, fmt.Errorf("%w: invalid bond", ErrInvalidTransaction)
+	}
+
+	return b, nil
+}
+
+// consensusKey decodes the consensus key of the validator, and checks whether the
+// validator is the address of the key.
+func consensusKey(validator string, key string) (*ecdsa.PublicKey, error) {
+	pub, err := crypto.DecodePublicKey(util.HexDecode(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if crypto.Address(pub) != validator {
+		return nil, crypto.ErrInvalidAddress
+	}
+
+	return pub, nil
+}
+
+// bondable checks whether the Stake or Unstake transaction is valid; stake can only be
+// bonded to a validator with a registered consensus key, and only stake that has been
+// bonded by the sender can be unbonded.
+func (j *journal) bondable(transaction Transaction) (Bond, error) {
+	b, err := transaction.bond()
+	if err != nil {
+		return Bond{}, err
+	}
+
+	if err = crypto.ValidateAddress(b.Validator); err != nil {
+		return Bond{}, fmt.Errorf("%w: invalid validator", ErrInvalidTransaction)
+	}
+
+	if len(b.Key) > 0 {
+		if _, err = consensusKey(b.Validator, b.Key); err != nil {
+			return Bond{}, fmt.Errorf("%w: key does not match validator", ErrInvalidTransaction)
+		}
+	} else if _, ok := j.am.keyOf(b.Validator); !ok && transaction.Type == Stake {
+		return Bond{}, fmt.Errorf("%w: validator has no registered key", ErrInvalidTransaction)
+	}
+
+	if 0 >= transaction.Amount {
+		return Bond{}, fmt.Errorf("%w: amount should be positive", ErrInvalidTransaction)
+	}
+
+	if transaction.Type == Unstake && transaction.Amount > j.am.getBond(transaction.Sender, b.Validator).Float64() {
+		return Bond{}, fmt.Errorf("%w: insufficient stake", ErrInvalidTransaction)
+	}
+
+	return b, nil
+}
+
+// stake applies a Stake or Unstake transaction; the funds of the sender are bonded
+// to, or unbonded from the validator. Unbonded funds are returned once the unbonding
+// period has passed.
+func (j *journal) stake(transaction Transaction) error {
+	b, err := j.bondable(transaction)
+	if err != nil {
+		return err
+	}
+
+	if transaction.Type == Unstake {
+		j.bond(transaction.Sender, b.Validator, -transaction.Amount)
+
+		if j.unbonding == 0 {
+			j.modify(transaction.Sender, transaction.Amount, true)
+
+			return nil
+		}
+
+		j.modify(transaction.Sender, 0, true)
+		j.unbond(unbondKey{transaction.Sender, b.Validator, j.am.currentEpoch() + j.unbonding}, transaction.Amount)
+
+		return nil
+	}
+
+	if err = j.debit(transaction.Sender, transaction.Amount, true); err != nil {
+		return err
+	}
+
+	j.bond(transaction.Sender, b.Validator, transaction.Amount)
+
+	if _, ok := j.am.keyOf(b.Validator); !ok {
+		j.registerKey(b.Validator, b.Key)
+	}
+
+	return nil
+}
+
+// releaseUnbonded returns the unbonded stakes whose epoch of release has started to their delegators.
+func (j *journal) releaseUnbonded(epoch uint64) {
+	for _, k := range j.am.releasable(epoch) {
+		amount := j.am.getUnbonding(k).Float64()
+
+		j.unbond(k, -amount)
+		j.credit(k.delegator, amount)
+	}
+}
@@ -0,0 +1,148 @@
+package blockchain
+
+import (
+	"testing"
+
+	"backend/crypto"
+	"backend/util"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StakingTestSuite struct {
+	suite.Suite
+	am        *accountModel
+	validator string
+	key       string
+}
+
+func (suite *StakingTestSuite) SetupTest() {
+	priv, validator := newValidator(suite.T())
+
+	suite.validator = validator
+	suite.key = util.HexEncode(crypto.EncodePublicKey(&priv.PublicKey))
+	suite.am = newAccountModel()
+
+	_ = suite.am.add("alice", 10)
+}
+
+func TestStakingTestSuite(t *testing.T) {
+	suite.Run(t, new(StakingTestSuite))
+}
+
+// bond creates a Stake or Unstake transaction of alice for the validator, which
+// registers the consensus key of the validator.
+func (suite *StakingTestSuite) bond(txType TxType, validator string, amount float64) Transaction {
+	t := suite.delegate(txType, validator, amount)
+	t.Data = string(util.JSONEncode(Bond{Validator: validator, Key: suite.key}))
+
+	return t
+}
+
+// delegate creates a Stake or Unstake transaction of alice for the validator, without
+// registering its consensus key.
+func (suite *StakingTestSuite) delegate(txType TxType, validator string, amount float64) Transaction {
+	account, _ := suite.am.get("alice")
+
+	return Transaction{
+		Sender: "alice",
+		Amount: amount,
+		Nonce:  account.Transactions,
+		Type:   txType,
+		Data:   string(util.JSONEncode(Bond{Validator: validator})),
+	}
+}
+
+func (suite *StakingTestSuite) TestStake() {
+	suite.NoError(newJournal(suite.am, 1).apply(suite.bond(Stake, suite.validator, 4)))
+
+	suite.True(ToCoin(6).Equal(suite.am.accounts["alice"].Balance))
+	suite.True(ToCoin(4).Equal(suite.am.getBond("alice", suite.validator)))
+	suite.Equal(map[string]Coin{suite.validator: ToCoin(4)}, suite.am.validators())
+
+	suite.NoError(newJournal(suite.am, 2).apply(suite.bond(Unstake, suite.validator, 4)))
+
+	suite.True(ToCoin(10).Equal(suite.am.accounts["alice"].Balance))
+	suite.Empty(suite.am.validators())
+}
+
+func (suite *StakingTestSuite) TestUnbonding() {
+	suite.NoError(newJournal(suite.am, 1).apply(suite.bond(Stake, suite.validator, 4)))
+	suite.NoError(newJournal(suite.am, 2).unbondingFor(2).apply(suite.bond(Unstake, suite.validator, 4)))
+
+	// the unstaked funds are held until the unbonding period has passed
+	suite.True(ToCoin(6).Equal(suite.am.accounts["alice"].Balance))
+	suite.Empty(suite.am.validators())
+
+	supply, _ := suite.am.supply()
+	suite.True(ToCoin(10).Equal(supply))
+
+	// and remain slashable
+	j := newJournal(suite.am, 3)
+	j.slash(suite.validator, 0.5)
+
+	supply, burned := suite.am.supply()
+	suite.True(ToCoin(8).Equal(supply))
+	suite.True(ToCoin(2).Equal(burned))
+
+	j.advance(1, 0)
+	suite.True(ToCoin(6).Equal(suite.am.accounts["alice"].Balance))
+
+	j.advance(2, 0)
+	suite.True(ToCoin(8).Equal(suite.am.accounts["alice"].Balance))
+	suite.Empty(suite.am.unbonding)
+
+	// every change can be reverted
+	j.rollback()
+
+	suite.True(ToCoin(6).Equal(suite.am.accounts["alice"].Balance))
+	suite.True(ToCoin(4).Equal(suite.am.getUnbonding(unbondKey{"alice", suite.validator, 2})))
+}
+
+func (suite *StakingTestSuite) TestStakeInvalid() {
+	suite.ErrorIs(newJournal(suite.am, 1).apply(suite.bond(Stake, "validator", 4)), ErrInvalidTransaction)
+	suite.ErrorIs(newJournal(suite.am, 1).apply(suite.bond(Stake, suite.validator, 0)), ErrInvalidTransaction)
+	suite.Error(newJournal(suite.am, 1).apply(suite.bond(Stake, suite.validator, 11)))
+	suite.ErrorIs(newJournal(suite.am, 1).apply(suite.bond(Unstake, suite.validator, 1)), ErrInvalidTransaction)
+
+	suite.Empty(suite.am.validators())
+}
+
+func (suite *StakingTestSuite) TestRegisterKey() {
+	_, other := newValidator(suite.T())
+
+	// the key should be registered along with the first stake, and match the validator
+	suite.ErrorIs(newJournal(suite.am, 1).apply(suite.delegate(Stake, suite.validator, 4)), ErrInvalidTransaction)
+	suite.ErrorIs(newJournal(suite.am, 1).apply(suite.bond(Stake, other, 4)), ErrInvalidTransaction)
+
+	j := newJournal(suite.am, 1)
+
+	suite.NoError(j.apply(suite.bond(Stake, suite.validator, 4)))
+
+	key, ok := suite.am.keyOf(suite.validator)
+
+	suite.True(ok)
+	suite.Equal(suite.key, key)
+
+	// stake can be bonded to a registered validator without its key
+	suite.NoError(j.apply(suite.delegate(Stake, suite.validator, 2)))
+	suite.True(ToCoin(6).Equal(suite.am.getBond("alice", suite.validator)))
+
+	j.rollback()
+
+	_, ok = suite.am.keyOf(suite.validator)
+
+	suite.False(ok)
+}
+
+func (suite *StakingTestSuite) TestStakeRollback() {
+	j := newJournal(suite.am, 1)
+
+	suite.NoError(j.apply(suite.bond(Stake, suite.validator, 4)))
+
+	j.rollback()
+
+	suite.True(ToCoin(10).Equal(suite.am.accounts["alice"].Balance))
+	suite.True(ToCoin(0).Equal(suite.am.getBond("alice", suite.validator)))
+	suite.Empty(suite.am.validators())
+}
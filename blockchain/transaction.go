@@ -1,6 +1,7 @@
 package blockchain
 
 import (
+	"crypto/ecdsa"
 	"crypto/sha256"
 	"errors"
 	"fmt"
@@ -13,26 +14,46 @@ import (
 type TxType string
 
 const (
-	Stake    TxType = "stake"
-	Regular  TxType = "regular"
-	Reward   TxType = "reward"
-	Fee      TxType = "fee"
-	Penalty  TxType = "penalty"
-	Exchange TxType = "exchange"
+	Stake      TxType = "stake"
+	Regular    TxType = "regular"
+	Reward     TxType = "reward"
+	Fee        TxType = "fee"
+	Penalty    TxType = "penalty"
+	Exchange   TxType = "exchange"
+	Lock       TxType = "lock"
+	Claim      TxType = "claim"
+	Refund     TxType = "refund"
+	Deploy     TxType = "deploy"
+	Call       TxType = "call"
+	Register   TxType = "register"
+	Renew      TxType = "renew"
+	Transfer   TxType = "transfer"
+	Unstake    TxType = "unstake"
+	Unjail     TxType = "unjail"
+	Commitment TxType = "commitment"
+	Reveal     TxType = "reveal"
+	Propose    TxType = "propose"
+	Ballot     TxType = "ballot"
 )
 
 // ErrInvalidTransaction is the base error when a transaction is invalid.
 var ErrInvalidTransaction = errors.New("invalid transaction")
 
 // Transaction represents a transaction within the blockchain.
+// The sender and receiver are addresses; the public key of the sender is
+// revealed along with the signature. The tip is paid to the validator of the
+// block on top of the base fee; see FeeMarket.
 type Transaction struct {
 	Sender    string  `json:"sender"`
+	PublicKey string  `json:"publicKey"`
 	Receiver  string  `json:"receiver"`
 	Signature string  `json:"signature"`
 	Amount    float64 `json:"amount"`
+	Tip       float64 `json:"tip,omitempty"`
 	Nonce     uint64  `json:"nonce"`
 	Timestamp int64   `json:"timestamp"`
 	Type      TxType  `json:"type"`
+	Data      string  `json:"data,omitempty"`
 }
 
 // String returns the transaction as a string.
@@ -40,23 +61,55 @@ func (t Transaction) String() string {
 	return fmt.Sprintf("%#v", t)
 }
 
-// Hash returns the hash of the transaction.
+// Hash returns the hash of the transaction; the signature is not part of it, as the
+// signature is created over the hash.
 func (t Transaction) Hash() []byte {
+	t.Signature = ""
+
 	h := sha256.New()
 	h.Write([]byte(t.String()))
 
 	return h.Sum(nil)
 }
 
-// Verify verifies if the signature is valid.
+// Sign signs the hash of the transaction with the private key of the sender, and
+// reveals its public key.
+func (t *Transaction) Sign(priv *ecdsa.PrivateKey) error {
+	t.PublicKey = util.HexEncode(crypto.EncodePublicKey(&priv.PublicKey))
+
+	sig, err := crypto.Sign(priv, t.Hash())
+	if err != nil {
+		return err
+	}
+
+	t.Signature = util.HexEncode(sig)
+
+	return nil
+}
+
+// Verify verifies if the signature of the hash is valid, and whether the public key belongs
+// to the sender. The receiver should be a valid address for transaction types that
+// transfer funds to another account.
 func (t Transaction) Verify() error {
 	// decode public key
-	key, err := crypto.DecodePublicKey(util.HexDecode(t.Sender))
+	key, err := crypto.DecodePublicKey(util.HexDecode(t.PublicKey))
 	if err != nil {
 		return err
 	}
 
-	if !crypto.Verify(key, []byte("test"), util.HexDecode(t.Signature)) {
+	if crypto.Address(key) != t.Sender {
+		return fmt.Errorf("%w: public key does not match sender", ErrInvalidTransaction)
+	}
+
+	switch t.Type {
+	case Regular, Exchange, Reward, Fee, Penalty, Lock, Call, Transfer:
+		if err = crypto.ValidateAddress(t.Receiver); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidTransaction, err.Error())
+		}
+	case Stake, Unstake, Claim, Refund, Deploy, Register, Renew, Unjail, Commitment, Reveal, Propose, Ballot:
+	}
+
+	if !crypto.Verify(key, t.Hash(), util.HexDecode(t.Signature)) {
 		return fmt.Errorf("%w: invalid signature", ErrInvalidTransaction)
 	}
 
@@ -32,3 +32,22 @@ func (suite *TransactionTestSuite) TestTransactionSignature() {
 
 	assert.True(suite.T(), crypto.Verify(suite.pub, []byte("signature"), sig))
 }
+
+func (suite *TransactionTestSuite) TestTransactionVerify() {
+	_, _, receiver, _ := wallet.NewKeyPair("", "")
+
+	t := Transaction{Sender: crypto.Address(suite.pub), Receiver: crypto.Address(receiver), Amount: 1, Type: Exchange}
+	suite.Require().NoError(t.Sign(suite.priv))
+	suite.NoError(t.Verify())
+
+	// the signature does not cover another transaction
+	tampered := t
+	tampered.Amount = 100
+
+	suite.ErrorIs(tampered.Verify(), ErrInvalidTransaction)
+
+	retargeted := t
+	retargeted.Receiver = crypto.Address(suite.pub)
+
+	suite.ErrorIs(retargeted.Verify(), ErrInvalidTransaction)
+}
@@ -0,0 +1,275 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"backend/crypto"
+	"backend/util"
+)
+
+// errInvalidChain is the base error when a chain is invalid.
+var errInvalidChain = errors.New("invalid chain")
+
+// Eligibility checks whether the validator of the given block was allowed to forge it,
+// under the rules at the height of the block.
+type Eligibility func(block Block, rules Rules) error
+
+// Election returns the proposers of the given slot, ordered by rank, given the seed of
+// the randomness beacon and the stakes of the validators in the epoch of the slot. At
+// most Ranks proposers are returned. An error is returned when no validator could be
+// elected.
+type Election func(seed []byte, slot uint64, stakes map[string]Coin) ([]string, error)
+
+// Certification checks whether the certificate of the given block is approved by the
+// quorum (a percentage) of the total weight of the validators, given their stakes in
+// the epoch of the block, under the rules at the height of the block.
+type Certification func(block Block, stakes map[string]Coin, quorum int, rules Rules) error
+
+// defaultElection does not elect validators; anyone may forge a block.
+func defaultElection([]byte, uint64, map[string]Coin) ([]string, error) {
+	return nil, nil
+}
+
+// defaultCertification does not require any votes.
+func defaultCertification(Block, map[string]Coin, int, Rules) error {
+	return nil
+}
+
+// defaultEligibility only requires a block to have a validator.
+func defaultEligibility(block Block, _ Rules) error {
+	if len(block.Validator) == 0 {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "missing validator")
+	}
+
+	return nil
+}
+
+// validateGenesis validates the genesis block.
+func validateGenesis(block Block) error {
+	_, pub, err := crypto.Genesis()
+	if err != nil {
+		return err
+	}
+
+	address := crypto.Address(pub)
+
+	if block.Height != 0 || len(block.PrevHash) != 0 {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "invalid genesis linkage")
+	}
+
+	if len(block.Transactions) != 1 {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "invalid genesis transactions")
+	}
+
+	t := block.Transactions[0]
+
+	if t.Sender != address || t.Receiver != address || t.Type != Exchange {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "invalid genesis transaction")
+	}
+
+	if !crypto.Verify(pub, []byte("genesis"), util.HexDecode(t.Signature)) {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "invalid genesis signature")
+	}
+
+	tr, err := newMerkleTree(hashTransactions(block.Transactions))
+	if err != nil {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "failed to create tree")
+	}
+
+	if util.HexEncode(tr.root.hash) != block.MerkleRoot {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "merkle root does not match")
+	}
+
+	return nil
+}
+
+// validateTransactions validates every transaction of the block, and applies
+// them through the given journal. The journal should be rolled back when an
+// error is returned, as it might hold a part of the transactions.
+func validateTransactions(j *journal, block Block) error {
+	for _, t := range block.Transactions {
+		if err := t.Verify(); err != nil {
+			return err
+		}
+
+		if err := j.apply(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// params holds the consensus parameters that blocks are validated against, besides
+// their structure.
+type params struct {
+	eligible   Eligibility
+	elect      Election
+	certified  Certification
+	quorum     int
+	clock      Clock
+	punishment Punishment
+	downtime   Downtime
+	fees       FeeMarket
+	limits     Limits
+	schedule   Schedule
+}
+
+// bootstrapping checks whether the block of the given slot is forged while the network
+// bootstraps: no validator has bonded stake within the genesis epoch, as stakes only
+// take effect in the next epoch. When the election requires stake, the blocks of the
+// genesis epoch are neither elected nor certified, such that the first stakes can be
+// bonded; any later epoch without validators does not accept blocks at all.
+func (p params) bootstrapping(epoch uint64, seed []byte, slot uint64, stakes map[string]Coin) bool {
+	if epoch > 0 || len(stakes) > 0 {
+		return false
+	}
+
+	_, err := p.elect(seed, slot, stakes)
+
+	return err != nil
+}
+
+// validateNext validates the block that follows the last block, and applies its
+// evidence, the liveness of the validators, and its transactions to the account model.
+// The block should be created within its slot by an eligible validator that was elected
+// by the stakes of its epoch, be certified by the quorum of these stakes, and respect
+// the parameters as changed through governance and the rules of the forks that are
+// active at its height.
+// The returned journal holds the changes made by the block.
+func validateNext(p params, am *accountModel, last Block, block Block) (*journal, error) {
+	if err := block.validate(last); err != nil {
+		return nil, err
+	}
+
+	rules := p.schedule.Rules(block.Height)
+
+	if err := p.eligible(block, rules); err != nil {
+		return nil, err
+	}
+
+	if err := p.clock.verify(block); err != nil {
+		return nil, err
+	}
+
+	epoch := p.clock.EpochOf(block.Slot)
+	stakes := am.validatorsAt(epoch)
+	voters := am.validatorsAt(p.clock.EpochOf(last.Slot))
+
+	// proposers are elected by slot alone before the beacon fork
+	var seed string
+
+	if rules.Active(BeaconFork) {
+		seed = am.beaconAt(epoch).Seed
+	}
+
+	if block.Beacon != seed {
+		return nil, fmt.Errorf("%w, %s", ErrInvalidBlock, "beacon does not match")
+	}
+
+	bootstrap := p.bootstrapping(epoch, util.HexDecode(seed), block.Slot, stakes)
+
+	if !bootstrap {
+		if err := block.elected(p.elect, util.HexDecode(seed), stakes); err != nil {
+			return nil, err
+		}
+	}
+
+	// the proposals that passed change the parameters from the block of their activation onwards
+	j := newJournal(am, block.Height).under(rules)
+
+	if rules.Active(GovernanceFork) {
+		j.govern(stakes)
+	}
+
+	p = p.governed(am.getParameters())
+	baseFee := p.baseFee(last)
+
+	if !bootstrap {
+		if err := p.certified(block, stakes, p.quorum, rules); err != nil {
+			j.rollback()
+
+			return nil, err
+		}
+
+		// certificates are not verified before the certificate fork
+		j.certified = rules.Active(CertificateFork)
+	}
+
+	if !ToCoin(block.BaseFee).Equal(baseFee) {
+		j.rollback()
+
+		return nil, fmt.Errorf("%w, %s", ErrInvalidBlock, "base fee does not match")
+	}
+
+	if err := p.limits.check(block.Transactions); err != nil {
+		j.rollback()
+
+		return nil, err
+	}
+
+	j.charging(baseFee, p.fees.GasPrice, block.Validator).unbondingFor(p.punishment.Unbonding)
+	j.advance(epoch, p.punishment.Withhold)
+
+	if err := validateEvidence(j, block, p.punishment); err != nil {
+		j.rollback()
+
+		return nil, err
+	}
+
+	if err := trackLiveness(j, p, last, block, util.HexDecode(seed), stakes, voters); err != nil {
+		j.rollback()
+
+		return nil, err
+	}
+
+	if err := validateTransactions(j, block); err != nil {
+		j.rollback()
+
+		return nil, fmt.Errorf("%w, %s", ErrInvalidBlock, err.Error())
+	}
+
+	return j, nil
+}
+
+// validateBlocks validates the given blocks from genesis onwards, and returns the
+// account model that results from them. Every block should link to its predecessor,
+// have a valid merkle root, and only contain valid evidence, and transactions that are
+// signed, have a valid nonce and are covered by the balance of the sender; see validateNext.
+func validateBlocks(p params, blocks []Block) (*accountModel, error) {
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("%w: zero blocks", errInvalidChain)
+	}
+
+	if err := validateGenesis(blocks[0]); err != nil {
+		return nil, fmt.Errorf("%w: block 0: %s", errInvalidChain, err.Error())
+	}
+
+	am := newAccountModel()
+	am.fromBlocks(blocks[0])
+
+	for i := 1; i < len(blocks); i++ {
+		j, err := validateNext(p, am, blocks[i-1], blocks[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: block %d: %s", errInvalidChain, i, err.Error())
+		}
+
+		j.commit()
+	}
+
+	return am, nil
+}
+
+// sortTransactions sorts the transactions by sender, and by nonce for each sender;
+// this is the order in which transactions of one sender should be applied.
+func sortTransactions(transactions []Transaction) {
+	sort.SliceStable(transactions, func(i, j int) bool {
+		if transactions[i].Sender != transactions[j].Sender {
+			return transactions[i].Sender < transactions[j].Sender
+		}
+
+		return transactions[i].Nonce < transactions[j].Nonce
+	})
+}
@@ -0,0 +1,371 @@
+package blockchain
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"backend/crypto"
+	"backend/util"
+	"backend/wallet"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type ValidationTestSuite struct {
+	suite.Suite
+	bc        *Blockchain
+	genesis   string
+	priv      *ecdsa.PrivateKey
+	wallet    string
+	key       *ecdsa.PrivateKey
+	validator string
+}
+
+func (suite *ValidationTestSuite) SetupTest() {
+	priv, pub, _ := crypto.Genesis()
+	_, _, wpub, _ := wallet.NewKeyPair("", "")
+
+	suite.genesis = crypto.Address(pub)
+	suite.priv = priv
+	suite.wallet = crypto.Address(wpub)
+	suite.key, suite.validator = newValidator(suite.T())
+
+	suite.bc = NewBlockchain()
+	suite.bc.Init("validator")
+}
+
+func TestValidationTestSuite(t *testing.T) {
+	suite.Run(t, new(ValidationTestSuite))
+}
+
+// newValidator generates a consensus key, and returns it along with the validator it identifies.
+func newValidator(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	return priv, crypto.Address(&priv.PublicKey)
+}
+
+// transaction creates a new exchange transaction from genesis.
+func (suite *ValidationTestSuite) transaction(amount float64, nonce uint64) Transaction {
+	return suite.sign(suite.priv, Transaction{
+		Sender:    suite.genesis,
+		Receiver:  suite.wallet,
+		Amount:    amount,
+		Nonce:     nonce,
+		Timestamp: time.Now().Unix(),
+		Type:      Exchange,
+	})
+}
+
+// sign signs the transaction with the given key; transactions that are changed after
+// they have been created need to be signed again.
+func (suite *ValidationTestSuite) sign(key *ecdsa.PrivateKey, t Transaction) Transaction {
+	suite.Require().NoError(t.Sign(key))
+
+	return t
+}
+
+// params returns the consensus parameters of the blockchain.
+func (suite *ValidationTestSuite) params() params {
+	return suite.bc.params(suite.bc.Blocks[0])
+}
+
+// forge creates and adds a block containing the given transactions.
+func (suite *ValidationTestSuite) forge(transactions ...Transaction) Block {
+	for _, t := range transactions {
+		suite.Require().NoError(suite.bc.UpdateMempool(t))
+	}
+
+	block, err := suite.bc.CreateBlock(suite.key, 0)
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.bc.AddBlock(block))
+
+	return block
+}
+
+// block creates a block in the given slot that follows the previous block, signed by
+// the validator of the given key.
+func (suite *ValidationTestSuite) block(key *ecdsa.PrivateKey, prev Block, slot uint64, transactions ...Transaction) Block {
+	block, err := newBlock(crypto.Address(&key.PublicKey), prev.Height+1, slot, time.Now().Unix(), prev.Hash(), transactions)
+	suite.Require().NoError(err)
+
+	block.Beacon = util.HexEncode(suite.bc.Seed(slot))
+	suite.Require().NoError(block.Sign(key))
+
+	return block
+}
+
+func (suite *ValidationTestSuite) TestValidChain() {
+	suite.forge(suite.transaction(100, 1), suite.transaction(50, 2))
+
+	am, err := validateBlocks(suite.params(), suite.bc.Blocks)
+
+	suite.NoError(err)
+	suite.True(ToCoin(150).Equal(am.accounts[suite.wallet].Balance))
+	suite.Equal(uint64(1), suite.bc.Blocks[1].Height)
+}
+
+func (suite *ValidationTestSuite) TestInvalidMerkleRoot() {
+	suite.forge(suite.transaction(100, 1))
+
+	blocks := append([]Block{}, suite.bc.Blocks...)
+	blocks[1].Transactions = []Transaction{suite.transaction(1000, 1)}
+
+	_, err := validateBlocks(suite.params(), blocks)
+
+	suite.ErrorIs(err, errInvalidChain)
+}
+
+func (suite *ValidationTestSuite) TestInvalidLinkage() {
+	suite.forge(suite.transaction(100, 1))
+	suite.forge(suite.transaction(100, 2))
+
+	blocks := []Block{suite.bc.Blocks[0], suite.bc.Blocks[2]}
+
+	_, err := validateBlocks(suite.params(), blocks)
+
+	suite.ErrorIs(err, errInvalidChain)
+}
+
+func (suite *ValidationTestSuite) TestInvalidNonce() {
+	block := suite.block(suite.key, suite.bc.Last(), 1, suite.transaction(100, 7))
+
+	suite.ErrorIs(suite.bc.AddBlock(block), ErrInvalidBlock)
+	suite.Len(suite.bc.Blocks, 1)
+}
+
+func (suite *ValidationTestSuite) TestInsufficientFunds() {
+	t := suite.transaction(100, 0)
+	t.Sender = suite.wallet
+
+	block := suite.block(suite.key, suite.bc.Last(), 1, t)
+
+	suite.Error(suite.bc.ValidateBlock(block))
+}
+
+func (suite *ValidationTestSuite) TestBlockSignature() {
+	block := suite.block(suite.key, suite.bc.Last(), 1, suite.transaction(100, 1))
+
+	// a block can be verified regardless of the peer that relayed it
+	suite.NoError(suite.bc.ValidateBlock(block))
+
+	// the header cannot be altered after it has been signed
+	tampered := block
+	tampered.Slot = 2
+
+	suite.ErrorIs(suite.bc.ValidateBlock(tampered), ErrInvalidBlock)
+
+	// the block should be signed by the key of its validator
+	other, _ := newValidator(suite.T())
+	forged := block
+
+	suite.Require().NoError(forged.Sign(other))
+	suite.ErrorIs(suite.bc.ValidateBlock(forged), ErrInvalidBlock)
+
+	unsigned := block
+	unsigned.Signature = ""
+
+	suite.ErrorIs(suite.bc.ValidateBlock(unsigned), ErrInvalidBlock)
+}
+
+func (suite *ValidationTestSuite) TestIneligibleValidator() {
+	suite.forge(suite.transaction(100, 1))
+
+	suite.bc.SetEligibility(func(block Block, rules Rules) error {
+		return ErrInvalidBlock
+	})
+
+	_, err := validateBlocks(suite.params(), suite.bc.Blocks)
+
+	suite.ErrorIs(err, errInvalidChain)
+}
+
+func (suite *ValidationTestSuite) TestElectedValidator() {
+	key, elected := newValidator(suite.T())
+
+	suite.bc.SetElection(func(seed []byte, slot uint64, stakes map[string]Coin) ([]string, error) {
+		return []string{elected}, nil
+	})
+
+	block := suite.block(suite.key, suite.bc.Last(), 1, suite.transaction(100, 1))
+
+	suite.ErrorIs(suite.bc.ValidateBlock(block), ErrInvalidBlock)
+
+	block = suite.block(key, suite.bc.Last(), 1, suite.transaction(100, 1))
+
+	suite.NoError(suite.bc.ValidateBlock(block))
+}
+
+func (suite *ValidationTestSuite) TestInitFallback() {
+	suite.forge(suite.transaction(100, 1))
+
+	valid := append([]Block{}, suite.bc.Blocks...)
+
+	suite.forge(suite.transaction(100, 2))
+
+	corrupted := append([]Block{}, suite.bc.Blocks...)
+	corrupted[2].Validator = ""
+
+	bc := NewBlockchain()
+	bc.Init("validator", valid, corrupted)
+
+	suite.Equal(valid, bc.Blocks)
+
+	account, err := bc.GetAccount(suite.wallet)
+
+	suite.NoError(err)
+	suite.True(ToCoin(100).Equal(account.Balance))
+}
+
+func (suite *ValidationTestSuite) TestReinit() {
+	suite.forge(suite.transaction(100, 1))
+
+	valid := append([]Block{}, suite.bc.Blocks...)
+
+	suite.forge(suite.transaction(100, 2))
+
+	// the journals of the previous account model are discarded
+	suite.bc.Init("validator", valid)
+
+	_, err := suite.bc.RevertBlock()
+
+	suite.Error(err)
+	suite.Equal(valid, suite.bc.Blocks)
+
+	account, err := suite.bc.GetAccount(suite.wallet)
+
+	suite.NoError(err)
+	suite.True(ToCoin(100).Equal(account.Balance))
+}
+
+func (suite *ValidationTestSuite) TestAccountCopy() {
+	suite.forge(suite.transaction(100, 1))
+
+	// the returned account is a copy; changing it leaves the blockchain untouched
+	account, err := suite.bc.GetAccount(suite.wallet)
+	suite.Require().NoError(err)
+
+	account.Balance = ToCoin(0)
+
+	account, err = suite.bc.GetAccount(suite.wallet)
+
+	suite.NoError(err)
+	suite.True(ToCoin(100).Equal(account.Balance))
+
+	var decoded Blockchain
+
+	util.JSONDecode(util.JSONEncode(suite.bc), &decoded)
+
+	suite.Equal(suite.bc.Blocks, decoded.Blocks)
+	suite.Equal(suite.bc.Finalized, decoded.Finalized)
+}
+
+func (suite *ValidationTestSuite) TestElectionFromStakes() {
+	key, id := newValidator(suite.T())
+
+	t := suite.transaction(10, 1)
+	t.Receiver = ""
+	t.Type = Stake
+	t.Data = string(util.JSONEncode(Bond{Validator: id, Key: util.HexEncode(crypto.EncodePublicKey(&key.PublicKey))}))
+	t = suite.sign(suite.priv, t)
+
+	suite.forge(t)
+
+	// the stake takes effect in the next epoch
+	suite.Empty(suite.bc.Validators(2))
+	suite.Equal(map[string]Coin{id: ToCoin(10)}, suite.bc.Validators(defaultEpoch))
+
+	suite.bc.SetElection(electStaker)
+
+	// the block of the validator was forged before anyone had staked
+	_, err := validateBlocks(suite.params(), suite.bc.Blocks)
+	suite.NoError(err)
+
+	block := suite.block(suite.key, suite.bc.Last(), defaultEpoch, suite.transaction(100, 2))
+
+	suite.ErrorIs(suite.bc.ValidateBlock(block), ErrInvalidBlock)
+
+	block = suite.block(key, suite.bc.Last(), defaultEpoch, suite.transaction(100, 2))
+
+	suite.NoError(suite.bc.ValidateBlock(block))
+
+	// anyone may forge within the epoch of the stake
+	block = suite.block(suite.key, suite.bc.Last(), 2, suite.transaction(100, 2))
+
+	suite.NoError(suite.bc.ValidateBlock(block))
+
+	_, err = suite.bc.RevertBlock()
+	suite.NoError(err)
+	suite.Empty(suite.bc.Validators(defaultEpoch))
+
+	// no one may forge once the genesis epoch has passed without validators
+	block = suite.block(suite.key, suite.bc.Last(), defaultEpoch, suite.transaction(100, 1))
+
+	suite.ErrorIs(suite.bc.ValidateBlock(block), ErrInvalidBlock)
+}
+
+// electStaker elects the only staker; no one is elected without stakers.
+func electStaker(_ []byte, _ uint64, stakes map[string]Coin) ([]string, error) {
+	for k := range stakes {
+		return []string{k}, nil
+	}
+
+	return nil, ErrInvalidBlock
+}
+
+func (suite *ValidationTestSuite) TestCertification() {
+	key, id := newValidator(suite.T())
+
+	t := suite.transaction(10, 1)
+	t.Receiver = ""
+	t.Type = Stake
+	t.Data = string(util.JSONEncode(Bond{Validator: id, Key: util.HexEncode(crypto.EncodePublicKey(&key.PublicKey))}))
+	t = suite.sign(suite.priv, t)
+
+	suite.forge(t)
+
+	// the certificate should hold the vote of every staker
+	suite.bc.SetElection(electStaker)
+	suite.bc.SetCertification(func(block Block, stakes map[string]Coin, quorum int, rules Rules) error {
+		suite.Equal(defaultQuorum, quorum)
+		suite.Equal(block.Height, rules.Height)
+
+		if len(block.Certificate) != len(stakes) {
+			return ErrInvalidBlock
+		}
+
+		return nil
+	})
+
+	block := suite.block(key, suite.bc.Last(), defaultEpoch, suite.transaction(100, 2))
+
+	// a proposal is validated before it is voted on
+	suite.NoError(suite.bc.ValidateBlock(block))
+	suite.ErrorIs(suite.bc.AddBlock(block), ErrInvalidBlock)
+
+	// a chain is only accepted when every block is certified
+	_, err := validateBlocks(suite.params(), append(append([]Block{}, suite.bc.Blocks...), block))
+	suite.ErrorIs(err, errInvalidChain)
+
+	v := NewVote(id, block, true)
+	suite.Require().NoError(v.Sign(key))
+
+	block.Certificate = []Vote{v}
+
+	suite.NoError(suite.bc.AddBlock(block))
+
+	_, err = validateBlocks(suite.params(), suite.bc.Blocks)
+	suite.NoError(err)
+}
+
+func (suite *ValidationTestSuite) TestQuorum() {
+	suite.Error(suite.bc.SetQuorum(0))
+	suite.Error(suite.bc.SetQuorum(101))
+	suite.NoError(suite.bc.SetQuorum(51))
+	suite.Equal(51, suite.params().quorum)
+}
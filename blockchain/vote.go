@@ -0,0 +1,101 @@
+package blockchain
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"backend/crypto"
+	"backend/util"
+)
+
+// defaultQuorum is the default percentage of the total weight of the validators that
+// should approve a block.
+const defaultQuorum = 67
+
+// Vote is the vote of a validator on a proposed block, signed by the consensus key of
+// the validator. The slot and rank identify the proposal; a validator
+// should approve at most one block for every proposal.
+type Vote struct {
+	Validator string `json:"validator"`
+	Block     string `json:"block"`
+	Slot      uint64 `json:"slot"`
+	Rank      uint32 `json:"rank"`
+	Valid     bool   `json:"valid"`
+	PublicKey string `json:"publicKey"`
+	Signature string `json:"signature"`
+}
+
+// NewVote creates an unsigned Vote of the validator on the block.
+func NewVote(validator string, block Block, valid bool) Vote {
+	return Vote{
+		Validator: validator,
+		Block:     util.HexEncode(block.Hash()),
+		Slot:      block.Slot,
+		Rank:      block.Rank,
+		Valid:     valid,
+	}
+}
+
+// Payload returns the data that is signed by the validator.
+func (v Vote) Payload() []byte {
+	return []byte(fmt.Sprintf("%s:%s:%d:%d:%t", v.Validator, v.Block, v.Slot, v.Rank, v.Valid))
+}
+
+// Sign signs the Vote with the consensus key of its validator.
+func (v *Vote) Sign(priv *ecdsa.PrivateKey) error {
+	sig, err := crypto.Sign(priv, v.Payload())
+	if err != nil {
+		return err
+	}
+
+	v.PublicKey = util.HexEncode(crypto.EncodePublicKey(&priv.PublicKey))
+	v.Signature = util.HexEncode(sig)
+
+	return nil
+}
+
+// Verify verifies whether the public key is the consensus key of the validator, and
+// whether the vote has been signed by it.
+func (v Vote) Verify() error {
+	pub, err := consensusKey(v.Validator, v.PublicKey)
+	if err != nil {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "public key does not match validator")
+	}
+
+	if !crypto.Verify(pub, v.Payload(), util.HexDecode(v.Signature)) {
+		return fmt.Errorf("%w, %s", ErrInvalidBlock, "invalid vote signature")
+	}
+
+	return nil
+}
+
+// verifyCertificate verifies the quorum certificate of the block; see verifyVotes.
+// Whether the certificate holds enough votes is determined by the Certification.
+func (b Block) verifyCertificate() error {
+	return b.verifyVotes(b.Certificate)
+}
+
+// verifyVotes verifies whether every vote approves the block, is signed by its
+// validator, and is cast only once.
+func (b Block) verifyVotes(votes []Vote) error {
+	hash := util.HexEncode(b.Hash())
+	voted := make(map[string]struct{}, len(votes))
+
+	for _, v := range votes {
+		if !v.Valid || v.Block != hash || v.Slot != b.Slot || v.Rank != b.Rank {
+			return fmt.Errorf("%w, %s", ErrInvalidBlock, "vote does not approve block")
+		}
+
+		if _, ok := voted[v.Validator]; ok {
+			return fmt.Errorf("%w, %s", ErrInvalidBlock, "duplicate vote")
+		}
+
+		if err := v.Verify(); err != nil {
+			return err
+		}
+
+		voted[v.Validator] = struct{}{}
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signedVote creates a Vote on the block, signed by a new validator.
+func signedVote(t *testing.T, block Block) Vote {
+	t.Helper()
+
+	priv, validator := newValidator(t)
+
+	v := NewVote(validator, block, true)
+	require.NoError(t, v.Sign(priv))
+
+	return v
+}
+
+func TestVote(t *testing.T) {
+	block := Block{Validator: "validator", Height: 1}
+	v := signedVote(t, block)
+
+	assert.NoError(t, v.Verify())
+
+	v.Valid = false
+	assert.ErrorIs(t, v.Verify(), ErrInvalidBlock)
+}
+
+func TestCertificate(t *testing.T) {
+	block := Block{Validator: "validator", Height: 1}
+	hash := block.Hash()
+
+	v := signedVote(t, block)
+	block.Certificate = []Vote{v}
+
+	// the certificate is not part of the hash
+	assert.Equal(t, hash, block.Hash())
+	assert.NoError(t, block.verifyCertificate())
+
+	block.Certificate = []Vote{v, v}
+	assert.ErrorIs(t, block.verifyCertificate(), ErrInvalidBlock)
+
+	block.Certificate = []Vote{signedVote(t, Block{Validator: "other"})}
+	assert.ErrorIs(t, block.verifyCertificate(), ErrInvalidBlock)
+}
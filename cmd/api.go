@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"backend/blockchain"
 	"backend/crypto"
@@ -31,6 +31,15 @@ import (
 
 var errInvalidHost = errors.New("invalid host")
 
+var errInvalidParameter = errors.New("invalid parameter")
+
+const (
+	// historyLimit the default amount of transactions per page of history.
+	historyLimit = 25
+	// maxHistoryLimit the maximum amount of transactions per page of history.
+	maxHistoryLimit = 100
+)
+
 // API represents the HTTP API.
 type API struct {
 	server *http.Server
@@ -47,6 +56,30 @@ func NewAPI(port int, seed string) *API {
 	mux.HandleFunc("/wallets", wallets)
 	mux.HandleFunc("/balance", balance)
 	mux.HandleFunc("/stake", stake)
+	mux.HandleFunc("/unstake", unstake)
+	mux.HandleFunc("/validators", validators)
+	mux.HandleFunc("/liveness", liveness)
+	mux.HandleFunc("/unjail", unjail)
+	mux.HandleFunc("/beacon", beacon)
+	mux.HandleFunc("/fee", fee)
+	mux.HandleFunc("/proposals", proposals)
+	mux.HandleFunc("/parameters", parameters)
+	mux.HandleFunc("/propose", propose)
+	mux.HandleFunc("/ballot", ballot)
+	mux.HandleFunc("/history", history)
+	mux.HandleFunc("/height", height)
+	mux.HandleFunc("/lock", lock)
+	mux.HandleFunc("/claim", claim)
+	mux.HandleFunc("/refund", refund)
+	mux.HandleFunc("/escrow", escrow)
+	mux.HandleFunc("/deploy", deploy)
+	mux.HandleFunc("/call", call)
+	mux.HandleFunc("/contract", contract)
+	mux.HandleFunc("/receipt", receipt)
+	mux.HandleFunc("/register", register)
+	mux.HandleFunc("/renew", renew)
+	mux.HandleFunc("/transfer", transfer)
+	mux.HandleFunc("/name", registration)
 
 	return &API{
 		server: &http.Server{
@@ -139,7 +172,7 @@ func balance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sender := strings.TrimSpace(r.URL.Query().Get("sender"))
+	sender := resolve(r, "sender")
 
 	if len(sender) == 0 {
 		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
@@ -165,6 +198,106 @@ func balance(w http.ResponseWriter, r *http.Request) {
 	log.Debug().Str("endpoint", "balance").Msg("api: handled request")
 }
 
+// history returns a page of the transactions sent and received by an address to a caller.
+func history(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	address := resolve(r, "address")
+
+	if len(address) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	page, limit := 0, historyLimit
+
+	if p := strings.TrimSpace(r.URL.Query().Get("page")); len(p) > 0 {
+		v, err := strconv.Atoi(p)
+		if err != nil || 0 > v {
+			http.Error(w, "parameter 'page' invalid", http.StatusBadRequest)
+
+			return
+		}
+
+		page = v
+	}
+
+	if l := strings.TrimSpace(r.URL.Query().Get("limit")); len(l) > 0 {
+		v, err := strconv.Atoi(l)
+		if err != nil || 0 >= v || v > maxHistoryLimit {
+			http.Error(w, "parameter 'limit' invalid", http.StatusBadRequest)
+
+			return
+		}
+
+		limit = v
+	}
+
+	records, total := node.blockchain.History(address, page, limit)
+
+	resp := struct {
+		Records []blockchain.Record `json:"records"`
+		Page    int                 `json:"page"`
+		Limit   int                 `json:"limit"`
+		Total   int                 `json:"total"`
+	}{
+		Records: records,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	log.Debug().Str("endpoint", "history").Msg("api: handled request")
+}
+
+// height returns the height of the head of the blockchain, and the heights of the last
+// justified and finalized checkpoints to the caller. Blocks up to the finalized height
+// can never be reverted.
+func height(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	justified, finalized := node.blockchain.Finality()
+
+	resp := struct {
+		Head      uint64 `json:"head"`
+		Justified uint64 `json:"justified"`
+		Finalized uint64 `json:"finalized"`
+	}{
+		Head:      node.blockchain.Last().Height,
+		Justified: justified.Height,
+		Finalized: finalized.Height,
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	log.Debug().Str("endpoint", "height").Msg("api: handled request")
+}
+
 // transaction creates and returns a new transaction to the caller.
 func transaction(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -176,8 +309,8 @@ func transaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sender := strings.TrimSpace(r.URL.Query().Get("sender"))
-	receiver := strings.TrimSpace(r.URL.Query().Get("receiver"))
+	sender := resolve(r, "sender")
+	receiver := resolve(r, "receiver")
 	key := strings.TrimSpace(r.URL.Query().Get("key"))
 	amount := strings.TrimSpace(r.URL.Query().Get("amount"))
 
@@ -194,21 +327,25 @@ func transaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	priv, err := crypto.DecodePrivateKey(util.HexDecode(key))
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	// the tip is optional; the base fee is paid regardless
+	var tip float64
 
-		return
+	if v := strings.TrimSpace(r.URL.Query().Get("tip")); len(v) > 0 {
+		if tip, err = strconv.ParseFloat(v, 64); err != nil {
+			http.Error(w, "parameter 'tip' invalid", http.StatusBadRequest)
+
+			return
+		}
 	}
 
-	sig, err := signature(priv)
+	priv, err := crypto.DecodePrivateKey(util.HexDecode(key))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
 	}
 
-	t, err := node.CreateTransaction(sender, receiver, sig, f, blockchain.Regular)
+	t, err := node.CreateTransaction(sender, receiver, priv, f, tip, blockchain.Regular, "")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 
@@ -235,7 +372,7 @@ func freeMoney(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sender := strings.TrimSpace(r.URL.Query().Get("sender"))
+	sender := resolve(r, "sender")
 	amount := strings.TrimSpace(r.URL.Query().Get("amount"))
 
 	if len(sender) == 0 || len(amount) == 0 {
@@ -258,14 +395,7 @@ func freeMoney(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sig, err := signature(priv)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-
-		return
-	}
-
-	t, err := node.CreateTransaction(util.HexEncode(crypto.EncodePublicKey(pub)), sender, sig, f, blockchain.Exchange)
+	t, err := node.CreateTransaction(crypto.Address(pub), sender, priv, f, 0, blockchain.Exchange, "")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 
@@ -312,8 +442,22 @@ func wallets(w http.ResponseWriter, r *http.Request) {
 	log.Debug().Str("endpoint", "wallets").Msg("api: handled request")
 }
 
-// stake lets a user stake their currency.
+// stake lets a user bond their currency to a validator; the validator defaults to this node.
 func stake(w http.ResponseWriter, r *http.Request) {
+	bond(w, r, blockchain.Stake)
+
+	log.Debug().Str("endpoint", "stake").Msg("api: handled request")
+}
+
+// unstake lets a user unbond their currency from a validator; the validator defaults to this node.
+func unstake(w http.ResponseWriter, r *http.Request) {
+	bond(w, r, blockchain.Unstake)
+
+	log.Debug().Str("endpoint", "unstake").Msg("api: handled request")
+}
+
+// bond creates a Stake or Unstake transaction of the sender for a validator.
+func bond(w http.ResponseWriter, r *http.Request, txType blockchain.TxType) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method != http.MethodPost {
@@ -323,15 +467,16 @@ func stake(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sender := strings.TrimSpace(r.URL.Query().Get("sender"))
+	sender := resolve(r, "sender")
 	amount := strings.TrimSpace(r.URL.Query().Get("amount"))
 	key := strings.TrimSpace(r.URL.Query().Get("key"))
+	validator := strings.TrimSpace(r.URL.Query().Get("validator"))
 
-	priv, err := crypto.DecodePrivateKey(util.HexDecode(key))
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	// the consensus key of the validator is registered along with its first stake
+	b := blockchain.Bond{Validator: validator, Key: strings.TrimSpace(r.URL.Query().Get("validatorKey"))}
 
-		return
+	if len(validator) == 0 {
+		b = blockchain.Bond{Validator: node.Validator(), Key: util.HexEncode(crypto.EncodePublicKey(&node.key.PublicKey))}
 	}
 
 	f, err := strconv.ParseFloat(amount, 64)
@@ -341,31 +486,708 @@ func stake(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sig, err := signature(priv)
-	if err != nil {
+	createTransaction(w, sender, "", key, f, txType, b)
+}
+
+// validators returns the stake of every validator in the current epoch to the caller.
+func validators(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	stakes := make(map[string]float64)
+
+	// the validator set of the current epoch
+	slot := node.blockchain.Clock().Slot(time.Now())
+
+	for k, v := range node.blockchain.Validators(slot) {
+		stakes[k] = v.Float64()
+	}
+
+	if err := json.NewEncoder(w).Encode(stakes); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
 	}
 
-	t, err := node.CreateTransaction(sender, "", sig, f, blockchain.Stake)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	log.Debug().Str("endpoint", "validators").Msg("api: handled request")
+}
+
+// liveness returns the proposals and votes every tracked validator missed within the
+// window, and whether it is jailed, to the caller.
+func liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 
 		return
 	}
 
-	if err = json.NewEncoder(w).Encode(t); err != nil {
+	if err := json.NewEncoder(w).Encode(node.blockchain.Liveness()); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
 	}
 
-	log.Debug().Str("endpoint", "stake").Msg("api: handled request")
+	log.Debug().Str("endpoint", "liveness").Msg("api: handled request")
+}
+
+// beacon returns the randomness beacon of the current epoch to the caller; the seed of
+// every block is recorded within the block itself.
+func beacon(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(node.blockchain.Beacon()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	log.Debug().Str("endpoint", "beacon").Msg("api: handled request")
+}
+
+// fee returns the base fee of the next block to the caller, along with an estimate of
+// the tip that gets a transaction into it.
+func fee(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(node.blockchain.EstimateFee()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	log.Debug().Str("endpoint", "fee").Msg("api: handled request")
+}
+
+// unjail lets a validator rejoin the validator set after it has been jailed for its
+// downtime; the transaction is signed by the consensus key of the validator.
+func unjail(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Access-Control-Allow-Methods", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	validator, key, ok := validatorKey(w, r)
+	if !ok {
+		return
+	}
+
+	createTransaction(w, validator, "", key, 0, blockchain.Unjail, nil)
+
+	log.Debug().Str("endpoint", "unjail").Msg("api: handled request")
+}
+
+// proposals returns every proposal to change a protocol parameter, by the hash of its
+// transaction, along with its votes to the caller.
+func proposals(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(node.blockchain.Proposals()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	log.Debug().Str("endpoint", "proposals").Msg("api: handled request")
+}
+
+// parameters returns the current value of every protocol parameter that can be changed
+// through governance to the caller.
+func parameters(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(node.blockchain.Parameters()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	log.Debug().Str("endpoint", "parameters").Msg("api: handled request")
+}
+
+// propose proposes, on behalf of the validator whose consensus key is given, to change a
+// protocol parameter to the value from the activation height onwards.
+func propose(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Access-Control-Allow-Methods", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	name := strings.TrimSpace(r.URL.Query().Get("parameter"))
+	value := strings.TrimSpace(r.URL.Query().Get("value"))
+	activation := strings.TrimSpace(r.URL.Query().Get("activation"))
+
+	if len(name) == 0 || len(value) == 0 || len(activation) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		http.Error(w, "parameter 'value' invalid", http.StatusBadRequest)
+
+		return
+	}
+
+	height, err := strconv.ParseUint(activation, 10, 64)
+	if err != nil {
+		http.Error(w, "parameter 'activation' invalid", http.StatusBadRequest)
+
+		return
+	}
+
+	validator, key, ok := validatorKey(w, r)
+	if !ok {
+		return
+	}
+
+	proposal := blockchain.Proposal{Parameter: name, Value: v, Activation: height}
+
+	createTransaction(w, validator, "", key, 0, blockchain.Propose, proposal)
+
+	log.Debug().Str("endpoint", "propose").Msg("api: handled request")
+}
+
+// ballot votes, on behalf of the validator whose consensus key is given, on a proposal to
+// change a protocol parameter; the vote is weighed by the stake of the validator.
+func ballot(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Access-Control-Allow-Methods", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	id := strings.TrimSpace(r.URL.Query().Get("proposal"))
+	approve, err := strconv.ParseBool(strings.TrimSpace(r.URL.Query().Get("approve")))
+
+	if len(id) == 0 || err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	validator, key, ok := validatorKey(w, r)
+	if !ok {
+		return
+	}
+
+	createTransaction(w, validator, "", key, 0, blockchain.Ballot, blockchain.Choice{Proposal: id, Approve: approve})
+
+	log.Debug().Str("endpoint", "ballot").Msg("api: handled request")
 }
 
-// signature creates a signature.
-// This should not be done on the api; but on the frontend wallet. Due to time constraints, it will happen here.
-func signature(priv *ecdsa.PrivateKey) ([]byte, error) {
-	return crypto.Sign(priv, []byte("test"))
+// lock locks funds of the sender in a hash time-locked contract. The receiver can claim the
+// funds by revealing the preimage of the hashlock before the deadline (block height).
+func lock(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Access-Control-Allow-Methods", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	sender := resolve(r, "sender")
+	receiver := resolve(r, "receiver")
+	key := strings.TrimSpace(r.URL.Query().Get("key"))
+	amount := strings.TrimSpace(r.URL.Query().Get("amount"))
+	hashlock := strings.TrimSpace(r.URL.Query().Get("hashlock"))
+	deadline := strings.TrimSpace(r.URL.Query().Get("deadline"))
+
+	if len(sender) == 0 || len(receiver) == 0 || len(key) == 0 || len(amount) == 0 || len(hashlock) == 0 || len(deadline) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	f, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		http.Error(w, "parameter 'amount' invalid", http.StatusBadRequest)
+
+		return
+	}
+
+	height, err := strconv.ParseUint(deadline, 10, 64)
+	if err != nil {
+		http.Error(w, "parameter 'deadline' invalid", http.StatusBadRequest)
+
+		return
+	}
+
+	contract := blockchain.Contract{Hashlock: hashlock, Deadline: height}
+
+	createTransaction(w, sender, receiver, key, f, blockchain.Lock, contract)
+
+	log.Debug().Str("endpoint", "lock").Msg("api: handled request")
+}
+
+// claim claims the funds of a hash time-locked contract by revealing the preimage.
+func claim(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Access-Control-Allow-Methods", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	sender := resolve(r, "sender")
+	key := strings.TrimSpace(r.URL.Query().Get("key"))
+	id := strings.TrimSpace(r.URL.Query().Get("lock"))
+	preimage := strings.TrimSpace(r.URL.Query().Get("preimage"))
+
+	if len(sender) == 0 || len(key) == 0 || len(id) == 0 || len(preimage) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	contract := blockchain.Contract{Lock: id, Preimage: preimage}
+
+	createTransaction(w, sender, sender, key, 0, blockchain.Claim, contract)
+
+	log.Debug().Str("endpoint", "claim").Msg("api: handled request")
+}
+
+// refund refunds the funds of a hash time-locked contract to its sender after the deadline.
+func refund(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Access-Control-Allow-Methods", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	sender := resolve(r, "sender")
+	key := strings.TrimSpace(r.URL.Query().Get("key"))
+	id := strings.TrimSpace(r.URL.Query().Get("lock"))
+
+	if len(sender) == 0 || len(key) == 0 || len(id) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	contract := blockchain.Contract{Lock: id}
+
+	createTransaction(w, sender, sender, key, 0, blockchain.Refund, contract)
+
+	log.Debug().Str("endpoint", "refund").Msg("api: handled request")
+}
+
+// validatorKey returns the address of the validator whose consensus key is given by the
+// request, along with the key; the response is written if the key is missing or invalid.
+func validatorKey(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	key := strings.TrimSpace(r.URL.Query().Get("key"))
+
+	if len(key) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return "", "", false
+	}
+
+	priv, err := crypto.DecodePrivateKey(util.HexDecode(key))
+	if err != nil {
+		http.Error(w, "parameter 'key' invalid", http.StatusBadRequest)
+
+		return "", "", false
+	}
+
+	return crypto.Address(&priv.PublicKey), key, true
+}
+
+// createTransaction creates a transaction whose data holds the given parameters, and writes it to the caller.
+func createTransaction(w http.ResponseWriter, sender, receiver, key string, amount float64, txType blockchain.TxType, params any) {
+	priv, err := crypto.DecodePrivateKey(util.HexDecode(key))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	t, err := node.CreateTransaction(sender, receiver, priv, amount, 0, txType, string(util.JSONEncode(params)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(t); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// escrow returns a hash time-locked contract, whose id is the hash of its lock transaction, to the caller.
+func escrow(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	id := strings.TrimSpace(r.URL.Query().Get("id"))
+
+	if len(id) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	e, err := node.blockchain.GetEscrow(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(e); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	log.Debug().Str("endpoint", "escrow").Msg("api: handled request")
+}
+
+// deploy deploys a new contract; the code should be hex encoded. The address of the
+// contract is derived from the hash of the returned transaction, as its receipt shows.
+func deploy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Access-Control-Allow-Methods", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	sender := resolve(r, "sender")
+	key := strings.TrimSpace(r.URL.Query().Get("key"))
+	code := strings.TrimSpace(r.URL.Query().Get("code"))
+
+	if len(sender) == 0 || len(key) == 0 || len(code) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	f, err := optionalFloat(r, "amount")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	createTransaction(w, sender, "", key, f, blockchain.Deploy, blockchain.Invocation{Code: code})
+
+	log.Debug().Str("endpoint", "deploy").Msg("api: handled request")
+}
+
+// call calls a contract with a comma separated list of integers as input, and the given gas limit;
+// the sender pays for the gas used at the gas price.
+func call(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Access-Control-Allow-Methods", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	sender := resolve(r, "sender")
+	key := strings.TrimSpace(r.URL.Query().Get("key"))
+	address := resolve(r, "contract")
+	gas := strings.TrimSpace(r.URL.Query().Get("gas"))
+	input := strings.TrimSpace(r.URL.Query().Get("input"))
+
+	if len(sender) == 0 || len(key) == 0 || len(address) == 0 || len(gas) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	f, err := optionalFloat(r, "amount")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	g, err := strconv.ParseUint(gas, 10, 64)
+	if err != nil {
+		http.Error(w, "parameter 'gas' invalid", http.StatusBadRequest)
+
+		return
+	}
+
+	args := make([]int64, 0)
+
+	for _, v := range strings.Split(input, ",") {
+		if len(strings.TrimSpace(v)) == 0 {
+			continue
+		}
+
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			http.Error(w, "parameter 'input' invalid", http.StatusBadRequest)
+
+			return
+		}
+
+		args = append(args, i)
+	}
+
+	createTransaction(w, sender, address, key, f, blockchain.Call, blockchain.Invocation{Input: args, Gas: g})
+
+	log.Debug().Str("endpoint", "call").Msg("api: handled request")
+}
+
+// contract returns a deployed contract, including its storage, to the caller.
+func contract(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	address := resolve(r, "address")
+
+	if len(address) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	c, err := node.blockchain.GetContract(address)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(c); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	log.Debug().Str("endpoint", "contract").Msg("api: handled request")
+}
+
+// receipt returns the result of a deploy or call transaction to the caller.
+func receipt(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	hash := strings.TrimSpace(r.URL.Query().Get("hash"))
+
+	if len(hash) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	rc, err := node.blockchain.GetReceipt(hash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(rc); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	log.Debug().Str("endpoint", "receipt").Msg("api: handled request")
+}
+
+// register registers a name that resolves to the sender; the fee is burned.
+func register(w http.ResponseWriter, r *http.Request) {
+	registerName(w, r, blockchain.Register)
+
+	log.Debug().Str("endpoint", "register").Msg("api: handled request")
+}
+
+// renew extends the registration of a name owned by the sender; the fee is burned.
+func renew(w http.ResponseWriter, r *http.Request) {
+	registerName(w, r, blockchain.Renew)
+
+	log.Debug().Str("endpoint", "renew").Msg("api: handled request")
+}
+
+// transfer transfers a name owned by the sender to the receiver.
+func transfer(w http.ResponseWriter, r *http.Request) {
+	registerName(w, r, blockchain.Transfer)
+
+	log.Debug().Str("endpoint", "transfer").Msg("api: handled request")
+}
+
+// registerName creates a Register, Renew or Transfer transaction for a name.
+func registerName(w http.ResponseWriter, r *http.Request, txType blockchain.TxType) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Access-Control-Allow-Methods", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	sender := resolve(r, "sender")
+	receiver := resolve(r, "receiver")
+	key := strings.TrimSpace(r.URL.Query().Get("key"))
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+
+	if len(sender) == 0 || len(key) == 0 || len(name) == 0 || (txType == blockchain.Transfer && len(receiver) == 0) {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	if err := blockchain.ValidateName(name); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	amount := blockchain.NameFee
+	if txType == blockchain.Transfer {
+		amount = 0
+	}
+
+	createTransaction(w, sender, receiver, key, amount, txType, blockchain.Registration{Name: name})
+}
+
+// registration returns the registration of a name, including its owner and expiry, to the caller.
+func registration(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+
+	if len(name) == 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	n, err := node.blockchain.GetName(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(n); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	log.Debug().Str("endpoint", "name").Msg("api: handled request")
+}
+
+// optionalFloat parses an optional float parameter; zero is returned when it is not set.
+func optionalFloat(r *http.Request, name string) (float64, error) {
+	v := strings.TrimSpace(r.URL.Query().Get(name))
+
+	if len(v) == 0 {
+		return 0, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: parameter '%s' invalid", errInvalidParameter, name)
+	}
+
+	return f, nil
+}
+
+// resolve returns the address of the given parameter. Registered names are
+// resolved to the address of their owner.
+func resolve(r *http.Request, name string) string {
+	v := strings.TrimSpace(r.URL.Query().Get(name))
+
+	if len(v) == 0 || crypto.ValidateAddress(v) == nil {
+		return v
+	}
+
+	if owner, err := node.blockchain.Resolve(v); err == nil {
+		return owner
+	}
+
+	return v
 }
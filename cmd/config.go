@@ -1,6 +1,7 @@
 package main
 
 import (
+	"strings"
 	"time"
 
 	"backend/util"
@@ -8,11 +9,20 @@ import (
 
 // Configuration all configuration required by the node.
 type Configuration struct {
-	Debug    bool
-	Port     int
-	APIPort  int
-	Interval string
-	Seed     string
+	Debug        bool
+	Port         int
+	APIPort      int
+	Interval     string
+	Seed         string
+	Consensus    string
+	Authorities  []string
+	Quorum       int
+	Epoch        int
+	ValidatorKey string
+	Strategy     string
+	BlockBytes   int
+	BlockGas     int
+	Forks        string
 }
 
 // getConfigFromEnv retrieves configuration from the environment, if environment
@@ -24,11 +34,49 @@ func getConfigFromEnv() Configuration {
 		interval = "20m"
 	}
 
+	// the amount of slots within an epoch
+	epoch := util.GetEnv("EPOCH", 32)
+
+	if 0 >= epoch {
+		epoch = 32
+	}
+
+	// the budget of a block; the transactions of a block should fit within it
+	blockBytes := util.GetEnv("BLOCK_BYTES", 512*1024)
+
+	if 0 >= blockBytes {
+		blockBytes = 512 * 1024
+	}
+
+	blockGas := util.GetEnv("BLOCK_GAS", 10000000)
+
+	if 0 >= blockGas {
+		blockGas = 10000000
+	}
+
+	// authorities are only used by proof of authority; a comma separated list of validators
+	authorities := make([]string, 0)
+
+	for _, a := range strings.Split(util.GetEnv("AUTHORITIES", ""), ",") {
+		if a = strings.TrimSpace(a); len(a) > 0 {
+			authorities = append(authorities, a)
+		}
+	}
+
 	return Configuration{
-		Debug:    util.GetEnv("DEBUG", false),
-		Port:     util.GetEnv("PORT", 30333),
-		APIPort:  util.GetEnv("API_PORT", 8080),
-		Interval: interval,
-		Seed:     util.GetEnv("DNS_SEED", "localhost:3000"),
+		Debug:        util.GetEnv("DEBUG", false),
+		Port:         util.GetEnv("PORT", 30333),
+		APIPort:      util.GetEnv("API_PORT", 8080),
+		Interval:     interval,
+		Seed:         util.GetEnv("DNS_SEED", "localhost:3000"),
+		Consensus:    util.GetEnv("CONSENSUS", "pos"),
+		Authorities:  authorities,
+		Quorum:       util.GetEnv("QUORUM", 67),
+		Epoch:        epoch,
+		ValidatorKey: util.GetEnv("VALIDATOR_KEY", "validator.pem"),
+		Strategy:     util.GetEnv("BLOCK_STRATEGY", "fee"),
+		BlockBytes:   blockBytes,
+		BlockGas:     blockGas,
+		Forks:        util.GetEnv("FORKS", ""),
 	}
 }
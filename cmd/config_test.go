@@ -13,4 +13,8 @@ func TestGetConfigFromEnv(t *testing.T) {
 	assert.Equal(t, 30333, config.Port)
 	assert.Equal(t, 8080, config.APIPort)
 	assert.Equal(t, "20m", config.Interval)
+	assert.Equal(t, "pos", config.Consensus)
+	assert.Empty(t, config.Authorities)
+	assert.Equal(t, 67, config.Quorum)
+	assert.Equal(t, 32, config.Epoch)
 }
@@ -1,44 +1,43 @@
 package main
 
 import (
-	"bytes"
+	"crypto/ecdsa"
+	"errors"
 	"fmt"
-	"io"
 	"os"
 	"os/signal"
-	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
 	"backend/blockchain"
 	"backend/consensus"
-	"backend/errors"
+	"backend/crypto"
 	"backend/networking"
+	"backend/protocol"
 	"backend/util"
 
-	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/rs/zerolog/log"
 )
 
-// This file is a mess; refactoring should be done.
-// Today is not that day.
-
 // version is interpolated during build time.
 var version string
 
-// blocks stores all received blockchain blocks from
-// other nodes within the network.
-var blocks = make([][]blockchain.Block, 0)
+// discovery is the time peers on the same LAN take to be discovered through mDNS.
+const discovery = time.Second
+
+// syncTimeout is the time peers get to send their blockchain during setup.
+const syncTimeout = 4 * time.Second
 
-// Node represents a singular blockchain node.
+// Node represents a singular blockchain node. It runs the protocol on the wall clock
+// and the peer-to-peer network.
 type Node struct {
 	Version    string
 	Uptime     time.Time
-	interval   time.Duration
 	network    *networking.Network
 	blockchain *blockchain.Blockchain
-	pos        *consensus.ProofOfStake
+	protocol   *protocol.Node
+	key        *ecdsa.PrivateKey
 	wg         sync.WaitGroup
 	ready      chan struct{}
 	close      chan struct{}
@@ -56,17 +55,105 @@ func NewNode(config Configuration) (*Node, error) {
 		return nil, err
 	}
 
+	// the consensus key is separate from the identity of the peer
+	key, err := loadKey(config.ValidatorKey)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := consensus.New(consensus.Config{
+		Engine:      config.Consensus,
+		ID:          crypto.Address(&key.PublicKey),
+		Authorities: config.Authorities,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bc := blockchain.NewBlockchain()
+
+	// a validator is identified by the address of its consensus key, which is
+	// verified by the blockchain itself
+	bc.SetEligibility(engine.Verify)
+
+	// the proposers of every slot are determined by the consensus engine, and every
+	// block should be certified by the quorum of the validators
+	bc.SetElection(engine.Proposers)
+	bc.SetCertification(engine.Certified)
+
+	if err = bc.SetQuorum(config.Quorum); err != nil {
+		return nil, err
+	}
+
+	// validators that equivocate are punished as determined by the consensus engine
+	bc.SetPunishment(engine.Punishment())
+	bc.SetDowntime(engine.Downtime())
+	bc.SetFeeMarket(engine.Fees())
+	bc.SetLimits(engine.Limits())
+
+	// every node should run the same fork schedule, such that upgrades switch on at the same block
+	schedule, err := blockchain.ParseSchedule(config.Forks)
+	if err != nil {
+		return nil, err
+	}
+
+	bc.SetSchedule(schedule)
+
+	// the transactions of the blocks this node proposes are selected by the strategy
+	strategy, err := blockchain.ParseStrategy(config.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	bc.SetBuilder(blockchain.Builder{Strategy: strategy, MaxBytes: config.BlockBytes, MaxGas: uint64(config.BlockGas)})
+
+	// the interval is the duration of a slot
+	if err = bc.SetClock(interval, uint64(config.Epoch)); err != nil {
+		return nil, err
+	}
+
 	return &Node{
 		Version:    version,
-		interval:   interval,
 		network:    net,
-		blockchain: blockchain.NewBlockchain(),
-		pos:        consensus.NewPoS(),
+		blockchain: bc,
+		protocol:   protocol.New(key, bc, engine, net, networking.SystemClock),
+		key:        key,
 		ready:      make(chan struct{}),
 		close:      make(chan struct{}),
 	}, nil
 }
 
+// loadKey loads the consensus key of the validator from the given file. A new key is
+// generated, and written to the file, if the file does not exist.
+func loadKey(path string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return crypto.PemDecodePrivateKey(data)
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.WriteFile(path, crypto.PemEncodePrivateKey(key), 0o600); err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("validator", crypto.Address(&key.PublicKey)).Msg("node: generated consensus key")
+
+	return key, nil
+}
+
+// Validator returns the validator of this node; the address of its consensus key.
+func (n *Node) Validator() string {
+	return crypto.Address(&n.key.PublicKey)
+}
+
 // Run starts all services required by the Node.
 func (n *Node) Run() {
 	// start network
@@ -74,9 +161,6 @@ func (n *Node) Run() {
 		log.Fatal().Err(err).Msg("node: network failed to run")
 	}
 
-	// setup network stream handlers
-	n.setStreamHandlers()
-
 	// setup and initialize the blockchain
 	n.setup()
 
@@ -87,12 +171,7 @@ func (n *Node) Run() {
 	n.listen()
 
 	// start the scheduler
-	if err := n.schedule(n.interval); err != nil {
-		log.Fatal().Err(err).Msg("node: failed to start scheduler")
-	}
-
-	// set initial stake
-	n.pos.Set(n.network.ID(), 0)
+	n.schedule()
 
 	// node done provisioning; set uptime for node
 	n.Uptime = time.Now()
@@ -100,62 +179,30 @@ func (n *Node) Run() {
 
 // AddTransaction adds a new transaction to the memory pool.
 func (n *Node) AddTransaction(transaction blockchain.Transaction) error {
-	// check if sender exists
-	tx, err := n.blockchain.GetAccount(transaction.Sender)
-	if err != nil {
-		log.Debug().Err(err).Msg("node: could not find account")
-
-		return err
-	}
-
-	// check if sender has sufficient funds
-	if transaction.Amount > tx.Balance.Float64() || 0 > transaction.Amount {
-		log.Debug().Err(err).Msg("node: account has insufficient funds")
-
-		return fmt.Errorf("%w: insufficient funds", blockchain.ErrInvalidTransaction)
-	}
-
-	// validate signature
-	if err = transaction.Verify(); err != nil {
-		return err
-	}
-
-	// check if sender has sufficient funds
-	if transaction.Amount > tx.Balance.Float64() || 0 > transaction.Amount {
-		return fmt.Errorf("%w: insufficient funds", blockchain.ErrInvalidTransaction)
-	}
-
-	// update the memory pool
-	if err = n.blockchain.UpdateMempool(transaction); err != nil {
-		log.Debug().Err(err).Msg("node: could not add transaction to mempool")
-
-		return err
-	}
-
-	// update sender
-	if err = n.blockchain.UpdateAccountModel(transaction.Sender, -transaction.Amount); err != nil {
-		log.Debug().Err(err).Msg("node: could not update account")
-
-		return err
-	}
-
-	log.Debug().Msg("node: added transaction")
-
-	return nil
+	return n.protocol.AddTransaction(transaction)
 }
 
-// CreateTransaction creates a new Transaction.
-func (n *Node) CreateTransaction(sender string, receiver string, signature []byte, amount float64, txType blockchain.TxType) (blockchain.Transaction, error) {
-	// check if sender exists
+// CreateTransaction creates a new Transaction, signed by the private key of the sender.
+// The public key of the sender is revealed along with the signature, and the tip is
+// paid to the validator on top of the base fee.
+// Data holds the parameters of the transaction type, and can be left empty.
+func (n *Node) CreateTransaction(sender string, receiver string, key *ecdsa.PrivateKey, amount float64, tip float64, txType blockchain.TxType, data string) (blockchain.Transaction, error) {
+	// check if sender exists; a validator needs no account to send the transactions
+	// of its consensus key
 	tx, err := n.blockchain.GetAccount(sender)
+	if err != nil && (txType == blockchain.Unjail || txType == blockchain.Commitment || txType == blockchain.Reveal || txType == blockchain.Propose || txType == blockchain.Ballot) {
+		tx, err = &blockchain.Account{Balance: blockchain.ToCoin(0)}, nil
+	}
+
 	if err != nil {
 		log.Debug().Err(err).Msg("node: could not find account")
 
 		return blockchain.Transaction{}, err
 	}
 
-	// check if sender has sufficient funds
-	if amount > tx.Balance.Float64() || 0 > amount {
+	// check if sender has sufficient funds; unstaking is covered by the stake instead,
+	// while the fees are checked by the memory pool
+	if (txType != blockchain.Unstake && amount+tip > tx.Balance.Float64()) || 0 > amount || 0 > tip {
 		log.Debug().Err(err).Msg("node: account has insufficient funds")
 
 		return blockchain.Transaction{}, fmt.Errorf("%w: insufficient funds", blockchain.ErrInvalidTransaction)
@@ -165,48 +212,23 @@ func (n *Node) CreateTransaction(sender string, receiver string, signature []byt
 	t := blockchain.Transaction{
 		Sender:    sender,
 		Receiver:  receiver,
-		Signature: util.HexEncode(signature),
 		Amount:    blockchain.ToCoin(amount).Float64(),
+		Tip:       blockchain.ToCoin(tip).Float64(),
 		Nonce:     tx.Transactions,
 		Timestamp: time.Now().Unix(),
 		Type:      txType,
+		Data:      data,
 	}
 
-	// validate signature
-	if err = t.Verify(); err != nil {
-		log.Debug().Err(err).Msg("node: could not verify transaction")
-
-		return blockchain.Transaction{}, err
-	}
-
-	// update the memory pool
-	if err = n.blockchain.UpdateMempool(t); err != nil {
-		log.Debug().Err(err).Msg("node: could not add transaction to mempool")
-
+	if err = t.Sign(key); err != nil {
 		return blockchain.Transaction{}, err
 	}
 
-	// set stake
-	if t.Type == blockchain.Stake {
-		n.pos.Transactions[t.String()] = struct{}{}
-
-		if err = n.pos.Update(n.network.ID(), t.Amount); err != nil {
-			log.Debug().Err(err).Msg("node: could not update stake")
-
-			return blockchain.Transaction{}, err
-		}
-	}
-
-	// update sender
-	if err = n.blockchain.UpdateAccountModel(t.Sender, -t.Amount); err != nil {
-		log.Debug().Err(err).Msg("node: could not update account")
-
+	// the transaction is published once it has been added to the memory pool
+	if err = n.protocol.Submit(t); err != nil {
 		return blockchain.Transaction{}, err
 	}
 
-	// publish message
-	n.network.Publish(networking.Transaction, util.JSONEncode(t))
-
 	log.Debug().Msg("node: created transaction")
 
 	return t, nil
@@ -222,8 +244,6 @@ func (n *Node) Stop() {
 		log.Error().Err(err).Msg("node: failed to close network")
 	}
 
-	n.network.Host.RemoveStreamHandler("/reply")
-
 	if err := n.blockchain.DumpJSON(); err != nil {
 		log.Error().Err(err).Msg("node: failed to dump blockchain")
 	}
@@ -231,45 +251,6 @@ func (n *Node) Stop() {
 	n.wg.Wait()
 }
 
-// setStreamHandlers sets the stream handlers that will handle individual request from other nodes.
-func (n *Node) setStreamHandlers() {
-	n.network.Host.SetStreamHandler("/reply", func(s network.Stream) {
-		var message networking.Message
-
-		msg, err := io.ReadAll(s)
-		if err != nil {
-			log.Error().Err(err).Msg("network: failed to read reply")
-		}
-
-		util.JSONDecode(msg, &message)
-
-		log.Debug().Str("topic", string(message.Topic)).Msg("network: received reply")
-
-		switch message.Topic {
-		case networking.Blockchain:
-			var b blockchain.Blockchain
-
-			util.JSONDecode(message.Payload, &b)
-
-			if len(b.Blocks) > 0 {
-				blocks = append(blocks, b.Blocks)
-			}
-		case networking.Consensus:
-			var r consensus.Resp
-
-			util.JSONDecode(message.Payload, &r)
-
-			n.pos.Responses = append(n.pos.Responses, r)
-		case networking.Stake:
-			if f, err := strconv.ParseFloat(string(message.Payload), 64); err == nil {
-				n.pos.Set(message.Peer, f)
-			}
-		case networking.Block, networking.Transaction, networking.Validator:
-			// ignore; requests are handled by the listener
-		}
-	})
-}
-
 // setup will set up the blockchain from either scratch or by using a file containing
 // the last known blockchain blocks from this current node (e.g. the Node has been shutdown,
 // and is in the process of being rebooted). The file will only be used as a reference; all
@@ -283,6 +264,8 @@ func (n *Node) setup() {
 		Int("node(s)", n.network.ConnectedPeers()).
 		Msg("node: synchronizing")
 
+	blocks := make([][]blockchain.Block, 0)
+
 	// get blocks from file
 	if data, err := n.blockchain.FromFile(); err == nil {
 		if len(data) != 0 {
@@ -290,37 +273,27 @@ func (n *Node) setup() {
 		}
 	}
 
-	// get blocks from peers
-	time.AfterFunc(time.Second, func() {
-		if n.network.ConnectedPeers() > 0 {
-			n.network.Request(networking.Blockchain)
-		}
-	})
+	time.Sleep(discovery)
 
-	n.wg.Add(1)
-
-	// wait for replies
-	time.AfterFunc(5*time.Second, func() {
-		defer n.wg.Done()
+	// get blocks from peers; the call is done once every peer replied, or timed out
+	for _, r := range n.network.Ask(networking.Blockchain, []byte("request"), syncTimeout).Collect() {
+		if r.Err != nil {
+			log.Debug().Err(r.Err).Str("peer", r.Peer).Msg("node: no blockchain from peer")
 
-		b := make([]blockchain.Block, 0)
-
-		if len(blocks) > 0 {
-			for _, data := range blocks {
-				if len(data) > len(b) {
-					b = data
-				}
-			}
+			continue
 		}
 
-		// initialize blockchain
-		n.blockchain.Init(n.network.ID(), b)
+		var b blockchain.Blockchain
 
-		// reset blocks
-		blocks = nil
-	})
+		util.JSONDecode(r.Payload, &b)
 
-	n.wg.Wait()
+		if len(b.Blocks) > 0 {
+			blocks = append(blocks, b.Blocks)
+		}
+	}
+
+	// initialize blockchain; every candidate will be validated
+	n.blockchain.Init(n.Validator(), blocks...)
 
 	log.Info().
 		Int("node(s)", n.network.ConnectedPeers()).
@@ -329,44 +302,23 @@ func (n *Node) setup() {
 	close(n.ready)
 }
 
-// schedule starts an internal ticker with given interval.
-// each interval an attempt will be made to forge a new block on the blockchain.
-func (n *Node) schedule(interval time.Duration) error {
-	if interval == 0 {
-		return errors.ErrInvalidArgument("interval can only be non-zero")
-	}
-
+// schedule starts the slot clock; the protocol acts at the start of every slot.
+func (n *Node) schedule() {
 	n.wg.Add(1)
 
 	go func() {
 		defer n.wg.Done()
 
-		ticker := time.NewTicker(interval)
-
 		for {
+			clock := n.blockchain.Clock()
+			slot := clock.Slot(time.Now()) + 1
+			timer := time.NewTimer(time.Until(clock.Start(slot)))
+
 			select {
-			case <-ticker.C:
-				// request stake from other nodes
-				n.network.Request(networking.Stake)
-
-				// wait for replies
-				time.AfterFunc(5*time.Second, func() {
-					validator, err := n.pos.Winner()
-					if err != nil {
-						// no stakers; new block will be created by this node
-						validator = n.network.ID()
-					}
-
-					// publish the node that will create the block
-					n.network.Publish(networking.Validator, []byte(validator))
-
-					// if this node is the validator; create block
-					if validator == n.network.ID() {
-						n.forge()
-					}
-				})
+			case <-timer.C:
+				n.protocol.Tick(slot)
 			case <-n.close:
-				ticker.Stop()
+				timer.Stop()
 
 				return
 			}
@@ -374,81 +326,10 @@ func (n *Node) schedule(interval time.Duration) error {
 	}()
 
 	log.Debug().Msg("node: scheduler started")
-
-	return nil
-}
-
-// forge Forges a new block.
-func (n *Node) forge() {
-	// wait a bit before forging block
-	time.AfterFunc(5*time.Second, func() {
-		// create block with a max of 1000 transactions, returns an error if there are no transactions
-		block, err := n.blockchain.CreateBlock(n.network.ID(), 1000)
-		if err != nil {
-			log.Debug().Err(err).Msg("node: failed to create block")
-
-			return
-		}
-
-		n.network.Publish(networking.Consensus, util.JSONEncode(block))
-
-		// wait for (consensus) replies
-		time.AfterFunc(5*time.Second, func() {
-			responses := len(n.pos.Responses)
-			valid := 0
-
-			for _, v := range n.pos.Responses {
-				if bytes.Equal(block.Hash(), v.Data) {
-					if v.Valid {
-						valid++
-					}
-				}
-			}
-
-			value := 100
-
-			if responses != 0 {
-				value = valid / responses * 100
-			}
-
-			// hardcoded value; meaning that it will not pass if there are only two nodes
-			// should be done differently
-			if value >= 66 {
-				n.blockchain.AddBlock(block, n.network.ID())
-
-				for _, t := range block.Transactions {
-					if _, ok := n.pos.Transactions[t.String()]; ok {
-						if err = n.pos.Update(n.network.ID(), -t.Amount); err != nil {
-							log.Debug().Err(err).Msg("node: failed to update stake")
-						}
-						delete(n.pos.Transactions, t.String())
-					}
-				}
-
-				n.network.Publish(networking.Block, util.JSONEncode(block))
-			}
-
-			// reset stakers
-			v, _ := n.pos.GetStake(n.network.ID())
-			n.pos.Clear()
-			n.pos.Set(n.network.ID(), v.Float64())
-
-			// reset responses
-			n.pos.Responses = make([]consensus.Resp, 0)
-
-			// remove validator
-			delete(n.pos.Validators, n.network.ID())
-		})
-	})
-}
-
-// reply sends a reply to another node using the network's Reply method.
-func (n *Node) reply(peer string, topic networking.Topic, payload []byte) {
-	n.network.Reply(peer, topic, payload)
 }
 
-// listen listens to incoming traffic from all nodes that this Node is connected to.
-// note: refactor this.
+// listen listens to incoming traffic from all nodes that this Node is connected to,
+// and passes every message to the protocol.
 func (n *Node) listen() {
 	n.wg.Add(1)
 
@@ -461,74 +342,16 @@ func (n *Node) listen() {
 			select {
 			case <-n.close:
 				return
-			case msg := <-net.Subs[networking.Transaction].Messages: // transaction
-				var t blockchain.Transaction
-
-				util.JSONDecode(msg.Payload, &t)
-
-				if err := n.AddTransaction(t); err != nil {
-					log.Error().Err(err).Msg("node: failed to add transaction")
-				}
-			case msg := <-net.Subs[networking.Block].Messages: // block
-				var b blockchain.Block
-
-				util.JSONDecode(msg.Payload, &b)
-
-				if _, ok := n.pos.Validators[msg.Peer]; ok {
-					delete(n.pos.Validators, msg.Peer)
-
-					n.blockchain.AddBlock(b, msg.Peer)
-
-					for _, t := range b.Transactions {
-						if _, ok := n.pos.Transactions[t.String()]; ok {
-							if err := n.pos.Update(n.network.ID(), -t.Amount); err != nil {
-								log.Debug().Err(err).Msg("node: failed to update stake")
-							}
-
-							delete(n.pos.Transactions, t.String())
-						}
-					}
-
-					v, _ := n.pos.GetStake(n.network.ID())
-					n.pos.Clear()
-					n.pos.Set(n.network.ID(), v.Float64())
-				}
-			case msg := <-net.Subs[networking.Blockchain].Messages: // blockchain
-				if len(n.blockchain.Blocks) > 0 {
-					n.reply(msg.Peer, networking.Blockchain, util.JSONEncode(n.blockchain))
-				}
-			case msg := <-net.Subs[networking.Stake].Messages: // stake
-				if stk, err := n.pos.GetStake(n.network.ID()); err == nil {
-					n.network.Reply(msg.Peer, networking.Stake, util.JSONEncode(stk.Float64()))
-				}
-			case msg := <-net.Subs[networking.Consensus].Messages: // consensus
-				var b blockchain.Block
-
-				util.JSONDecode(msg.Payload, &b)
-
-				resp := &consensus.Resp{
-					Data:  b.Hash(),
-					Valid: false,
-				}
-
-				err := b.Validate(n.blockchain.Blocks[len(n.blockchain.Blocks)-1], msg.Peer)
-				if err != nil {
-					n.network.Reply(msg.Peer, networking.Consensus, util.JSONEncode(resp))
-
-					return
-				}
-
-				resp.Valid = true
-
-				n.network.Reply(msg.Peer, networking.Consensus, util.JSONEncode(resp))
-			case msg := <-net.Subs[networking.Validator].Messages: // validator
-				// append validator to array, to keep track of validators.
-				n.pos.Validators[string(msg.Payload)] = struct{}{}
-
-				// if this node is the validator; create block
-				if string(msg.Payload) == n.network.ID() {
-					n.forge()
-				}
+			case msg := <-net.Subs[networking.Transaction].Messages:
+				n.protocol.Handle(msg)
+			case msg := <-net.Subs[networking.Block].Messages:
+				n.protocol.Handle(msg)
+			case msg := <-net.Subs[networking.Blockchain].Messages:
+				n.protocol.Handle(msg)
+			case msg := <-net.Subs[networking.Evidence].Messages:
+				n.protocol.Handle(msg)
+			case msg := <-net.Subs[networking.Consensus].Messages:
+				n.protocol.Handle(msg)
 			}
 		}
 	}()
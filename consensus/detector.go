@@ -0,0 +1,114 @@
+package consensus
+
+import (
+	"bytes"
+	"sync"
+
+	"backend/blockchain"
+)
+
+// proposal identifies the proposal a validator voted on, or made.
+type proposal struct {
+	validator string
+	slot      uint64
+	rank      uint32
+}
+
+// Detector detects validators that equivocate; it remembers the approving votes and
+// the proposed blocks that have been observed, and reports a validator that approves
+// a different block for the same proposal, or that proposes a different block for the
+// same slot and rank.
+type Detector struct {
+	mu     sync.Mutex
+	votes  map[proposal]blockchain.Vote
+	blocks map[proposal]blockchain.Block
+}
+
+// NewDetector creates a new Detector.
+func NewDetector() *Detector {
+	return &Detector{
+		votes:  make(map[proposal]blockchain.Vote),
+		blocks: make(map[proposal]blockchain.Block),
+	}
+}
+
+// Observe records the votes, and returns the evidence of every vote that conflicts with
+// an earlier vote of its validator. Votes that are not signed by their validator are ignored.
+func (d *Detector) Observe(votes ...blockchain.Vote) []blockchain.Evidence {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	evidence := make([]blockchain.Evidence, 0)
+
+	for _, v := range votes {
+		if !v.Valid || v.Verify() != nil {
+			continue
+		}
+
+		p := proposal{validator: v.Validator, slot: v.Slot, rank: v.Rank}
+
+		prev, ok := d.votes[p]
+		if !ok {
+			d.votes[p] = v
+
+			continue
+		}
+
+		if prev.Block != v.Block {
+			evidence = append(evidence, blockchain.Evidence{First: prev, Second: v})
+		}
+	}
+
+	return evidence
+}
+
+// Propose records the proposed blocks, and returns the evidence of every block that
+// conflicts with an earlier block of its validator. Blocks that are not signed by
+// their validator are ignored; the certificate is not part of the evidence.
+func (d *Detector) Propose(blocks ...blockchain.Block) []blockchain.Evidence {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	evidence := make([]blockchain.Evidence, 0)
+
+	for _, b := range blocks {
+		if b.VerifySignature() != nil {
+			continue
+		}
+
+		b.Certificate = nil
+
+		p := proposal{validator: b.Validator, slot: b.Slot, rank: b.Rank}
+
+		prev, ok := d.blocks[p]
+		if !ok {
+			d.blocks[p] = b
+
+			continue
+		}
+
+		if !bytes.Equal(prev.Hash(), b.Hash()) {
+			evidence = append(evidence, blockchain.Evidence{Proposals: []blockchain.Block{prev, b}})
+		}
+	}
+
+	return evidence
+}
+
+// Prune forgets the votes on proposals, and the proposed blocks, of slots before the given slot.
+func (d *Detector) Prune(slot uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for p := range d.votes {
+		if slot > p.slot {
+			delete(d.votes, p)
+		}
+	}
+
+	for p := range d.blocks {
+		if slot > p.slot {
+			delete(d.blocks, p)
+		}
+	}
+}
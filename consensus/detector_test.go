@@ -0,0 +1,68 @@
+package consensus
+
+import (
+	"testing"
+
+	"backend/blockchain"
+	"backend/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector(t *testing.T) {
+	alice, signAlice := validator(t)
+	_, signBob := validator(t)
+
+	d := NewDetector()
+	a := blockchain.Block{Validator: alice, Height: 1, Slot: 3}
+	b := blockchain.Block{Validator: alice, Height: 1, Slot: 3, Timestamp: 1}
+
+	assert.Empty(t, d.Observe(signAlice(a, true), signBob(a, true), signBob(b, false)))
+
+	evidence := d.Observe(signAlice(b, true))
+
+	require.Len(t, evidence, 1)
+	assert.NoError(t, evidence[0].Verify())
+	assert.Equal(t, alice, evidence[0].Validator())
+
+	// a backup proposal in the same slot is a different proposal
+	b.Rank = 1
+	assert.Empty(t, d.Observe(signAlice(b, true)))
+
+	d.Prune(4)
+	assert.Empty(t, d.Observe(signAlice(a, true)))
+}
+
+func TestDetectorProposals(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	propose := func(timestamp int64) blockchain.Block {
+		b := blockchain.Block{Validator: crypto.Address(&priv.PublicKey), Height: 1, Slot: 3, Timestamp: timestamp}
+		require.NoError(t, b.Sign(priv))
+
+		return b
+	}
+
+	d := NewDetector()
+	a := propose(1)
+
+	// the same block with its certificate does not conflict
+	certified := a
+	certified.Certificate = []blockchain.Vote{blockchain.NewVote(a.Validator, a, true)}
+
+	assert.Empty(t, d.Propose(a, certified))
+
+	// unsigned blocks are ignored
+	assert.Empty(t, d.Propose(blockchain.Block{Validator: a.Validator, Height: 1, Slot: 3, Timestamp: 2}))
+
+	evidence := d.Propose(propose(2))
+
+	require.Len(t, evidence, 1)
+	assert.NoError(t, evidence[0].Verify())
+	assert.Equal(t, a.Validator, evidence[0].Validator())
+
+	d.Prune(4)
+	assert.Empty(t, d.Propose(propose(3)))
+}
@@ -0,0 +1,71 @@
+package consensus
+
+import (
+	"fmt"
+
+	"backend/blockchain"
+)
+
+// Dev is a consensus Engine for a single node; the node forges every block itself,
+// without waiting for the votes of other nodes. It should only be used for development.
+type Dev struct {
+	id string
+}
+
+// NewDev creates a new development consensus instance for the node with given id.
+func NewDev(id string) *Dev {
+	return &Dev{id: id}
+}
+
+// Proposers always returns the node itself, without any backups.
+func (d *Dev) Proposers([]byte, uint64, map[string]blockchain.Coin) ([]string, error) {
+	return []string{d.id}, nil
+}
+
+// Verify only accepts blocks forged by the node itself.
+func (d *Dev) Verify(block blockchain.Block, _ blockchain.Rules) error {
+	if block.Validator != d.id {
+		return fmt.Errorf("%w, %s", blockchain.ErrInvalidBlock, "validator is not this node")
+	}
+
+	return nil
+}
+
+// Vote ignores the vote; no votes are needed.
+func (d *Dev) Vote(blockchain.Vote) {}
+
+// Certificate holds no votes; no votes are needed.
+func (d *Dev) Certificate(blockchain.Block) []blockchain.Vote {
+	return nil
+}
+
+// Certified always accepts the block.
+func (d *Dev) Certified(blockchain.Block, map[string]blockchain.Coin, int, blockchain.Rules) error {
+	return nil
+}
+
+// Commit does nothing.
+func (d *Dev) Commit(blockchain.Block) {}
+
+// Reset does nothing.
+func (d *Dev) Reset(uint64, uint32) {}
+
+// Punishment does not punish validators; there are no other validators.
+func (d *Dev) Punishment() blockchain.Punishment {
+	return blockchain.Punishment{}
+}
+
+// Downtime does not track liveness; there are no other validators.
+func (d *Dev) Downtime() blockchain.Downtime {
+	return blockchain.Downtime{}
+}
+
+// Fees charges no fees; there is no congestion on a single node.
+func (d *Dev) Fees() blockchain.FeeMarket {
+	return blockchain.FeeMarket{}
+}
+
+// Limits limits the size and gas of the transactions of a block.
+func (d *Dev) Limits() blockchain.Limits {
+	return limits
+}
@@ -0,0 +1,93 @@
+package consensus
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"sort"
+
+	"backend/blockchain"
+	"backend/errors"
+)
+
+// Elect returns the validator that is allowed to forge the block following the
+// block with the given hash. The chance of a validator being elected is weighted
+// by its stake; given the same stakes and seed, every node elects the same validator.
+func Elect(stakes map[string]blockchain.Coin, seed []byte) (string, error) {
+	weights := weigh(stakes)
+	validators := make([]string, 0, len(weights))
+
+	total := new(big.Int)
+
+	for k, w := range weights {
+		validators = append(validators, k)
+		total.Add(total, w)
+	}
+
+	if len(validators) == 0 {
+		return "", errors.ErrInvalidOperation("no stakers")
+	}
+
+	// iteration order of a map is random; the order should be the same on every node
+	sort.Strings(validators)
+
+	// the draw covers the whole hash, such that it is uniform for large stakes as well
+	h := sha256.Sum256(seed)
+	draw := new(big.Int).Mod(new(big.Int).SetBytes(h[:]), total)
+
+	for _, v := range validators {
+		if draw.Cmp(weights[v]) < 0 {
+			return v, nil
+		}
+
+		draw.Sub(draw, weights[v])
+	}
+
+	// this should not happen; the draw is always lower than the total stake
+	return validators[len(validators)-1], nil
+}
+
+// Rank returns at most n distinct validators ordered by rank, for the given seed.
+// Every rank is elected from the validators that have not been elected yet, such that
+// the chance of being a backup proposer is weighted by stake as well.
+func Rank(stakes map[string]blockchain.Coin, seed []byte, n int) ([]string, error) {
+	remaining := make(map[string]blockchain.Coin, len(stakes))
+
+	for k, v := range stakes {
+		remaining[k] = v
+	}
+
+	ranked := make([]string, 0, n)
+
+	for r := 0; r < n; r++ {
+		// every rank has its own seed; the seed itself is left untouched
+		v, err := Elect(remaining, binary.BigEndian.AppendUint32(append([]byte{}, seed...), uint32(r)))
+		if err != nil {
+			break
+		}
+
+		ranked = append(ranked, v)
+		delete(remaining, v)
+	}
+
+	if len(ranked) == 0 {
+		return nil, errors.ErrInvalidOperation("no stakers")
+	}
+
+	return ranked, nil
+}
+
+// weigh returns the weight of every validator with a stake. Stakes are weighted
+// in cents, to avoid rounding differences between nodes; the weights are big integers,
+// as large stakes do not fit in 64 bits.
+func weigh(stakes map[string]blockchain.Coin) map[string]*big.Int {
+	weights := make(map[string]*big.Int, len(stakes))
+
+	for k, v := range stakes {
+		if w := v.Cents(); w.Sign() > 0 {
+			weights[k] = w
+		}
+	}
+
+	return weights
+}
@@ -0,0 +1,102 @@
+package consensus
+
+import (
+	"testing"
+
+	"backend/blockchain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElect(t *testing.T) {
+	stakes := map[string]blockchain.Coin{
+		"alice": blockchain.ToCoin(10),
+		"bob":   blockchain.ToCoin(30),
+		"carol": blockchain.ToCoin(0),
+	}
+
+	winner, err := Elect(stakes, []byte("seed"))
+
+	assert.NoError(t, err)
+
+	// every node should elect the same validator for the same seed
+	for i := 0; i < 10; i++ {
+		w, _ := Elect(stakes, []byte("seed"))
+
+		assert.Equal(t, winner, w)
+	}
+}
+
+func TestElectWeighted(t *testing.T) {
+	stakes := map[string]blockchain.Coin{
+		"alice": blockchain.ToCoin(10),
+		"bob":   blockchain.ToCoin(30),
+		"carol": blockchain.ToCoin(0),
+	}
+
+	wins := make(map[string]int)
+
+	for i := 0; i < 4000; i++ {
+		w, err := Elect(stakes, []byte{byte(i), byte(i >> 8)})
+
+		assert.NoError(t, err)
+
+		wins[w]++
+	}
+
+	assert.Zero(t, wins["carol"])
+	assert.InDelta(t, 3000, wins["bob"], 200)
+	assert.InDelta(t, 1000, wins["alice"], 200)
+}
+
+func TestElectLargeStakes(t *testing.T) {
+	// the stakes in cents, and their sum, do not fit in 64 bits
+	stakes := map[string]blockchain.Coin{
+		"alice": blockchain.ToCoin(1e18),
+		"bob":   blockchain.ToCoin(3e18),
+	}
+
+	wins := make(map[string]int)
+
+	for i := 0; i < 4000; i++ {
+		w, err := Elect(stakes, []byte{byte(i), byte(i >> 8)})
+
+		assert.NoError(t, err)
+
+		wins[w]++
+	}
+
+	assert.InDelta(t, 3000, wins["bob"], 200)
+	assert.InDelta(t, 1000, wins["alice"], 200)
+}
+
+func TestElectNoStakers(t *testing.T) {
+	_, err := Elect(map[string]blockchain.Coin{"alice": blockchain.ToCoin(0)}, []byte("seed"))
+
+	assert.Error(t, err)
+}
+
+func TestRank(t *testing.T) {
+	stakes := map[string]blockchain.Coin{
+		"alice": blockchain.ToCoin(10),
+		"bob":   blockchain.ToCoin(30),
+		"carol": blockchain.ToCoin(20),
+		"dave":  blockchain.ToCoin(0),
+	}
+
+	seed := []byte("seed")
+	ranked, err := Rank(stakes, seed, 5)
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"alice", "bob", "carol"}, ranked)
+	assert.Equal(t, []byte("seed"), seed)
+
+	// the ranks are the same for the same seed
+	again, _ := Rank(stakes, seed, 2)
+
+	assert.Equal(t, ranked[:2], again)
+
+	_, err = Rank(map[string]blockchain.Coin{"dave": blockchain.ToCoin(0)}, seed, 2)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+package consensus
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"backend/blockchain"
+	"backend/errors"
+	"backend/util"
+)
+
+// fees targets a hundred transactions per block, starting at a base fee of a cent; the
+// base fee changes by at most an eighth per block.
+var fees = blockchain.FeeMarket{Target: 100, Denominator: 8, Initial: 0.01, Minimum: 0.01, GasPrice: 0.0001}
+
+// limits allows a block to hold 512 KiB of transactions, that use at most ten million gas.
+var limits = blockchain.Limits{MaxBytes: 512 << 10, MaxGas: 10000000}
+
+// Engine is the consensus algorithm used by the node. It determines which validator
+// may forge the next block, verifies the validator of a block, and tallies the signed
+// votes of other validators on a proposed block. The stakes of the validators are
+// derived from the blockchain; engines that do not weigh by stake ignore them.
+type Engine interface {
+	// Proposers returns the validators that are allowed to forge the block in the given
+	// slot, as elected by the seed of the randomness beacon, ordered by rank; the first
+	// is the primary proposer.
+	Proposers(seed []byte, slot uint64, stakes map[string]blockchain.Coin) ([]string, error)
+	// Verify checks whether the validator of the block is allowed to forge blocks at all,
+	// under the rules at the height of the block.
+	Verify(block blockchain.Block, rules blockchain.Rules) error
+	// Vote records the vote of a validator on a proposed block.
+	Vote(vote blockchain.Vote)
+	// Certificate returns the votes that approve the proposed block.
+	Certificate(block blockchain.Block) []blockchain.Vote
+	// Certified checks whether the certificate of a block reaches the quorum (a
+	// percentage) of the total weight under the rules at the height of the block; it is
+	// verified by the blockchain for every block.
+	Certified(block blockchain.Block, stakes map[string]blockchain.Coin, quorum int, rules blockchain.Rules) error
+	// Commit is called once a block has been added to the blockchain.
+	Commit(block blockchain.Block)
+	// Reset clears the votes on the proposal of the given slot and rank.
+	Reset(slot uint64, rank uint32)
+	// Punishment returns the punishment of validators that equivocated.
+	Punishment() blockchain.Punishment
+	// Downtime returns the parameters of liveness tracking.
+	Downtime() blockchain.Downtime
+	// Fees returns the parameters of the base fee.
+	Fees() blockchain.FeeMarket
+	// Limits returns the maximum size and gas of the transactions of a block.
+	Limits() blockchain.Limits
+}
+
+// Config holds the configuration of an Engine.
+type Config struct {
+	// Engine is the name of the engine; either "pos", "poa" or "dev".
+	Engine string
+	// ID is the validator of this node; the address of its consensus key.
+	ID string
+	// Authorities are the validators of proof of authority.
+	Authorities []string
+}
+
+// New creates the Engine with the given configuration.
+func New(config Config) (Engine, error) {
+	switch config.Engine {
+	case "pos":
+		return NewPoS(), nil
+	case "poa":
+		return NewPoA(config.Authorities)
+	case "dev":
+		return NewDev(config.ID), nil
+	}
+
+	return nil, errors.ErrInvalidArgument("unknown consensus engine '%s'", config.Engine)
+}
+
+// ranked checks whether the rank of the block is accepted under the rules; backup
+// proposers only forge blocks from the ranks fork onwards.
+func ranked(block blockchain.Block, rules blockchain.Rules) error {
+	if block.Rank > 0 && !rules.Active(blockchain.RanksFork) {
+		return fmt.Errorf("%w, %s", blockchain.ErrInvalidBlock, "backup proposers are not active before the ranks fork")
+	}
+
+	return nil
+}
+
+// round identifies the proposal of a slot and rank.
+type round struct {
+	slot uint64
+	rank uint32
+}
+
+// ballot holds the votes that have been cast in every round; only the last vote of a
+// validator within a round is kept.
+type ballot struct {
+	mu     sync.Mutex
+	rounds map[round]map[string]blockchain.Vote
+}
+
+// newBallot creates a new ballot.
+func newBallot() *ballot {
+	return &ballot{rounds: make(map[round]map[string]blockchain.Vote)}
+}
+
+// add adds the vote to the round of its proposal; votes that are not signed by their
+// validator are ignored.
+func (b *ballot) add(vote blockchain.Vote) {
+	if vote.Verify() != nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r := round{slot: vote.Slot, rank: vote.Rank}
+
+	if _, ok := b.rounds[r]; !ok {
+		b.rounds[r] = make(map[string]blockchain.Vote)
+	}
+
+	b.rounds[r][vote.Validator] = vote
+}
+
+// approvals returns the votes that approve the block, ordered by validator.
+func (b *ballot) approvals(block blockchain.Block) []blockchain.Vote {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hash := util.HexEncode(block.Hash())
+	votes := b.rounds[round{slot: block.Slot, rank: block.Rank}]
+	approvals := make([]blockchain.Vote, 0, len(votes))
+
+	for _, v := range votes {
+		if v.Valid && v.Block == hash {
+			approvals = append(approvals, v)
+		}
+	}
+
+	sort.Slice(approvals, func(i, j int) bool {
+		return approvals[i].Validator < approvals[j].Validator
+	})
+
+	return approvals
+}
+
+// clear removes the votes of the round of the given slot and rank.
+func (b *ballot) clear(slot uint64, rank uint32) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.rounds, round{slot: slot, rank: rank})
+}
+
+// reached checks whether the weight of the validators within the certificate reaches
+// the quorum, as a percentage of the total weight. The quorum is never reached when
+// there is no weight at all.
+func reached(certificate []blockchain.Vote, weights map[string]*big.Int, quorum int) bool {
+	total, approved := new(big.Int), new(big.Int)
+
+	for _, w := range weights {
+		total.Add(total, w)
+	}
+
+	if total.Sign() == 0 {
+		return false
+	}
+
+	counted := make(map[string]struct{}, len(certificate))
+
+	for _, v := range certificate {
+		if w, ok := weights[v.Validator]; ok {
+			if _, ok = counted[v.Validator]; !ok {
+				approved.Add(approved, w)
+				counted[v.Validator] = struct{}{}
+			}
+		}
+	}
+
+	approved.Mul(approved, big.NewInt(100))
+	total.Mul(total, big.NewInt(int64(quorum)))
+
+	return approved.Cmp(total) >= 0
+}
@@ -0,0 +1,213 @@
+package consensus
+
+import (
+	"testing"
+
+	"backend/blockchain"
+	"backend/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validator creates a new validator, and returns its id and a function that signs votes.
+func validator(t *testing.T) (string, func(block blockchain.Block, valid bool) blockchain.Vote) {
+	t.Helper()
+
+	priv, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	id := crypto.Address(&priv.PublicKey)
+
+	return id, func(block blockchain.Block, valid bool) blockchain.Vote {
+		v := blockchain.NewVote(id, block, valid)
+		_ = v.Sign(priv)
+
+		return v
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{"pos", "dev"} {
+		_, err := New(Config{Engine: name, ID: "node"})
+
+		assert.NoError(t, err)
+	}
+
+	_, err := New(Config{Engine: "poa", ID: "node"})
+	assert.Error(t, err)
+
+	_, err = New(Config{Engine: "pow", ID: "node"})
+	assert.Error(t, err)
+}
+
+func TestDev(t *testing.T) {
+	dev := NewDev("node")
+
+	proposers, err := dev.Proposers(nil, 1, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"node"}, proposers)
+	assert.NoError(t, dev.Verify(blockchain.Block{Validator: "node"}, blockchain.Rules{}))
+	assert.ErrorIs(t, dev.Verify(blockchain.Block{Validator: "other"}, blockchain.Rules{}), blockchain.ErrInvalidBlock)
+
+	assert.Empty(t, dev.Certificate(blockchain.Block{}))
+	assert.NoError(t, dev.Certified(blockchain.Block{}, nil, 67, blockchain.Rules{}))
+}
+
+func TestPoA(t *testing.T) {
+	alice, signAlice := validator(t)
+	bob, signBob := validator(t)
+
+	poa, err := NewPoA([]string{alice, bob})
+	require.NoError(t, err)
+
+	first, _ := poa.Proposers(nil, 1, nil)
+	second, _ := poa.Proposers(nil, 2, nil)
+
+	// the authority of the next slot is the backup
+	assert.Equal(t, []string{bob, alice}, first)
+	assert.Equal(t, []string{alice, bob}, second)
+	assert.NoError(t, poa.Verify(blockchain.Block{Validator: alice}, blockchain.Rules{}))
+	assert.ErrorIs(t, poa.Verify(blockchain.Block{Validator: "carol"}, blockchain.Rules{}), blockchain.ErrInvalidBlock)
+
+	block := blockchain.Block{Validator: alice}
+
+	poa.Vote(signAlice(block, true))
+
+	block.Certificate = poa.Certificate(block)
+	assert.ErrorIs(t, poa.Certified(block, nil, 67, blockchain.Rules{}), blockchain.ErrInvalidBlock)
+
+	poa.Vote(signBob(block, true))
+
+	block.Certificate = poa.Certificate(block)
+	assert.Len(t, block.Certificate, 2)
+	assert.NoError(t, poa.Certified(block, nil, 67, blockchain.Rules{}))
+}
+
+func TestForks(t *testing.T) {
+	alice, _ := validator(t)
+
+	poa, err := NewPoA([]string{alice})
+	require.NoError(t, err)
+
+	schedule := blockchain.Schedule{blockchain.RanksFork: 10, blockchain.CertificateFork: 10}
+	backup := blockchain.Block{Validator: alice, Height: 9, Rank: 1}
+
+	// backup proposers forge blocks from the ranks fork onwards
+	for _, engine := range []Engine{poa, NewPoS()} {
+		assert.ErrorIs(t, engine.Verify(backup, schedule.Rules(9)), blockchain.ErrInvalidBlock)
+		assert.NoError(t, engine.Verify(backup, schedule.Rules(10)))
+
+		// certificates are verified from the certificate fork onwards
+		assert.NoError(t, engine.Certified(backup, nil, 67, schedule.Rules(9)))
+		assert.ErrorIs(t, engine.Certified(backup, nil, 67, schedule.Rules(10)), blockchain.ErrInvalidBlock)
+	}
+}
+
+func TestCertificate(t *testing.T) {
+	alice, signAlice := validator(t)
+	bob, signBob := validator(t)
+	carol, signCarol := validator(t)
+
+	block := blockchain.Block{Validator: alice}
+	pos := NewPoS()
+
+	// no stakers; the quorum can never be reached
+	assert.ErrorIs(t, pos.Certified(block, nil, 67, blockchain.Rules{}), blockchain.ErrInvalidBlock)
+
+	stakes := map[string]blockchain.Coin{
+		alice: blockchain.ToCoin(20),
+		bob:   blockchain.ToCoin(50),
+		carol: blockchain.ToCoin(30),
+	}
+
+	pos.Vote(signAlice(block, true))
+	pos.Vote(signCarol(block, true))
+	pos.Vote(signBob(block, false))
+
+	// 50 of 100 is not a supermajority
+	block.Certificate = pos.Certificate(block)
+	assert.Len(t, block.Certificate, 2)
+	assert.ErrorIs(t, pos.Certified(block, stakes, 67, blockchain.Rules{}), blockchain.ErrInvalidBlock)
+
+	// unless the quorum is a half
+	assert.NoError(t, pos.Certified(block, stakes, 50, blockchain.Rules{}))
+
+	// the last vote of a validator counts
+	pos.Vote(signBob(block, true))
+
+	certificate := pos.Certificate(block)
+
+	block.Certificate = certificate
+	assert.NoError(t, pos.Certified(block, stakes, 67, blockchain.Rules{}))
+
+	block.Certificate = certificate[:1]
+	assert.ErrorIs(t, pos.Certified(block, stakes, 67, blockchain.Rules{}), blockchain.ErrInvalidBlock)
+
+	// the votes of other rounds are kept
+	next := blockchain.Block{Validator: alice, Slot: 1}
+
+	pos.Vote(signAlice(next, true))
+	pos.Reset(block.Slot, block.Rank)
+
+	assert.Empty(t, pos.Certificate(block))
+	assert.Len(t, pos.Certificate(next), 1)
+
+	// the tally does not overflow on large stakes
+	large := map[string]blockchain.Coin{
+		alice: blockchain.ToCoin(1e16),
+		bob:   blockchain.ToCoin(1e16),
+		carol: blockchain.ToCoin(1e16),
+	}
+
+	block.Certificate = certificate
+	assert.NoError(t, pos.Certified(block, large, 67, blockchain.Rules{}))
+}
+
+func TestForgedVote(t *testing.T) {
+	alice, _ := validator(t)
+	_, signBob := validator(t)
+
+	block := blockchain.Block{Validator: alice}
+	pos := NewPoS()
+
+	// a vote of alice that has been signed by bob
+	v := signBob(block, true)
+	v.Validator = alice
+
+	pos.Vote(v)
+
+	assert.Empty(t, pos.Certificate(block))
+}
+
+func TestPoSProposer(t *testing.T) {
+	pos := NewPoS()
+	seed := []byte("seed")
+
+	_, err := pos.Proposers(seed, 2, nil)
+	assert.Error(t, err)
+
+	proposers, err := pos.Proposers(seed, 2, map[string]blockchain.Coin{"alice": blockchain.ToCoin(10)})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, proposers)
+
+	// the primary proposer changes between slots
+	stakes := map[string]blockchain.Coin{"alice": blockchain.ToCoin(10), "bob": blockchain.ToCoin(10)}
+	primaries := make(map[string]struct{})
+
+	for slot := uint64(2); slot < 34; slot++ {
+		proposers, _ = pos.Proposers(seed, slot, stakes)
+		primaries[proposers[0]] = struct{}{}
+
+		assert.Len(t, proposers, 2)
+	}
+
+	assert.Len(t, primaries, 2)
+
+	// the seed elects the proposers; the same seed elects the same proposers
+	first, _ := pos.Proposers(seed, 2, stakes)
+	again, _ := pos.Proposers([]byte("seed"), 2, stakes)
+
+	assert.Equal(t, first, again)
+}
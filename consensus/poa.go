@@ -0,0 +1,115 @@
+package consensus
+
+import (
+	"fmt"
+	"math/big"
+
+	"backend/blockchain"
+	"backend/errors"
+)
+
+// ProofOfAuthority is a consensus Engine in which a fixed set of authorities take
+// turns in forging blocks. It is meant for private deployments.
+type ProofOfAuthority struct {
+	authorities []string
+	ballot      *ballot
+}
+
+// NewPoA creates a new proof of authority consensus instance. The order of the
+// authorities determines the order in which they forge blocks, and should be the
+// same on every node. A block is accepted when the quorum of the authorities
+// approve it.
+func NewPoA(authorities []string) (*ProofOfAuthority, error) {
+	if len(authorities) == 0 {
+		return nil, errors.ErrInvalidArgument("no authorities")
+	}
+
+	return &ProofOfAuthority{
+		authorities: authorities,
+		ballot:      newBallot(),
+	}, nil
+}
+
+// Proposers returns the authority whose turn it is to forge the block in the given slot,
+// followed by the authorities whose turn it is in the next slots as backups.
+func (poa *ProofOfAuthority) Proposers(_ []byte, slot uint64, _ map[string]blockchain.Coin) ([]string, error) {
+	n := uint64(len(poa.authorities))
+	proposers := make([]string, 0, blockchain.Ranks)
+
+	for r := uint64(0); r < uint64(blockchain.Ranks) && r < n; r++ {
+		proposers = append(proposers, poa.authorities[(slot+r)%n])
+	}
+
+	return proposers, nil
+}
+
+// Verify checks whether the validator of the block is one of the authorities, and
+// whether its rank is accepted by the rules.
+func (poa *ProofOfAuthority) Verify(block blockchain.Block, rules blockchain.Rules) error {
+	for _, a := range poa.authorities {
+		if a == block.Validator {
+			return ranked(block, rules)
+		}
+	}
+
+	return fmt.Errorf("%w, %s", blockchain.ErrInvalidBlock, "validator is not an authority")
+}
+
+// Vote records the vote of a validator on a proposed block.
+func (poa *ProofOfAuthority) Vote(vote blockchain.Vote) {
+	poa.ballot.add(vote)
+}
+
+// Certificate returns the votes that approve the proposed block.
+func (poa *ProofOfAuthority) Certificate(block blockchain.Block) []blockchain.Vote {
+	return poa.ballot.approvals(block)
+}
+
+// Certified checks whether the certificate of the block holds the votes of the
+// quorum of the authorities, from the certificate fork onwards.
+func (poa *ProofOfAuthority) Certified(block blockchain.Block, _ map[string]blockchain.Coin, quorum int, rules blockchain.Rules) error {
+	if rules.Active(blockchain.CertificateFork) && !reached(block.Certificate, poa.weights(), quorum) {
+		return fmt.Errorf("%w, %s", blockchain.ErrInvalidBlock, "quorum not reached")
+	}
+
+	return nil
+}
+
+// weights returns the weight of every authority; every authority has the same weight.
+func (poa *ProofOfAuthority) weights() map[string]*big.Int {
+	weights := make(map[string]*big.Int, len(poa.authorities))
+
+	for _, a := range poa.authorities {
+		weights[a] = big.NewInt(1)
+	}
+
+	return weights
+}
+
+// Commit does nothing; the authorities are fixed.
+func (poa *ProofOfAuthority) Commit(blockchain.Block) {}
+
+// Reset clears the votes on the proposal of the given slot and rank.
+func (poa *ProofOfAuthority) Reset(slot uint64, rank uint32) {
+	poa.ballot.clear(slot, rank)
+}
+
+// Punishment does not punish validators; the authorities are fixed, and do not stake.
+func (poa *ProofOfAuthority) Punishment() blockchain.Punishment {
+	return blockchain.Punishment{}
+}
+
+// Downtime does not track liveness; the authorities are fixed, and do not stake.
+func (poa *ProofOfAuthority) Downtime() blockchain.Downtime {
+	return blockchain.Downtime{}
+}
+
+// Fees charges a base fee that tracks how full blocks are.
+func (poa *ProofOfAuthority) Fees() blockchain.FeeMarket {
+	return fees
+}
+
+// Limits limits the size and gas of the transactions of a block.
+func (poa *ProofOfAuthority) Limits() blockchain.Limits {
+	return limits
+}
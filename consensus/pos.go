@@ -1,130 +1,96 @@
 package consensus
 
 import (
-	"math/rand"
-	"sync"
-	"time"
+	"encoding/binary"
+	"fmt"
 
 	"backend/blockchain"
-	"backend/errors"
 )
 
-// This is a very simple implementation of proof of stake. Should probably be refactored,
-// but due to time constraints this will not happen.
+// punishment jails validators that equivocated for four epochs, and slashes 5% of their
+// stake; validators that withhold their secret lose 1% of their stake. Unstaked funds
+// remain slashable for two epochs.
+var punishment = blockchain.Punishment{Jail: 4, Slash: 0.05, Withhold: 0.01, Unbonding: 2}
 
-// ProofOfStake is the consensus algorithm used by the node.
-// Probably should refactor this to use another struct, instead of putting it all in this struct.
-type ProofOfStake struct {
-	Transactions map[string]any // remove (and insert) from map is O(1) whilst removing from an array is O(n) (iterate through array).
-	Validators   map[string]any
-	Responses    []Resp
-	stakers      map[string]blockchain.Coin
-	sync.RWMutex
-}
+// downtime jails validators that missed half of their votes, or eight proposals within
+// the last 32 blocks; they can unjail themselves after two epochs.
+var downtime = blockchain.Downtime{Window: 32, MaxMissedProposals: 8, MaxMissedVotes: 16, Cooldown: 2}
 
-// Resp the response of the consensus.
-// Not optimal, but it works (for now).
-type Resp struct {
-	Data  []byte
-	Valid bool
+// ProofOfStake is a consensus Engine in which the validator is elected by stake.
+// The stakes are derived from the stake transactions within the blockchain.
+type ProofOfStake struct {
+	ballot *ballot
 }
 
-// NewPoS creates a new proof of stake consensus instance.
+// NewPoS creates a new proof of stake consensus instance. A block is accepted when
+// the validators that approve it hold the quorum of the total stake.
 func NewPoS() *ProofOfStake {
 	return &ProofOfStake{
-		stakers:      make(map[string]blockchain.Coin),
-		Transactions: make(map[string]any),
-		Validators:   make(map[string]any),
-		Responses:    make([]Resp, 0),
+		ballot: newBallot(),
 	}
 }
 
-// Winner returns the validator that is allowed to forge a new block.
-func (pos *ProofOfStake) Winner() (string, error) {
-	var node string
-
-	pos.Lock()
-	defer pos.Unlock()
-
-	pool := make([]string, 0, len(pos.stakers))
-
-	for k, v := range pos.stakers {
-		if v.Float64() > 0 {
-			pool = append(pool, k)
-		}
-	}
-
-	if len(pool) == 0 {
-		return node, errors.ErrInvalidOperation("no stakers")
-	}
-
-	r := rand.New(rand.NewSource(time.Now().Unix()))
-	node = pool[r.Intn(len(pool))]
-
-	return node, nil
+// Proposers returns the validators that are elected to forge the block in the given slot
+// by the seed of the randomness beacon; see Rank. Every slot elects different validators,
+// such that a slot without a block does not stall the blockchain.
+func (pos *ProofOfStake) Proposers(seed []byte, slot uint64, stakes map[string]blockchain.Coin) ([]string, error) {
+	return Rank(stakes, binary.BigEndian.AppendUint64(append([]byte{}, seed...), slot), int(blockchain.Ranks))
 }
 
-// GetStake returns the stake of a given node.
-func (pos *ProofOfStake) GetStake(node string) (blockchain.Coin, error) {
-	if !pos.Exists(node) {
-		return blockchain.Coin{}, errors.ErrInvalidOperation("node does not exist")
+// Verify only requires a block to have a validator, and a rank that is accepted by the
+// rules; the election is verified by the blockchain, as it holds the stakes at every block.
+func (pos *ProofOfStake) Verify(block blockchain.Block, rules blockchain.Rules) error {
+	if len(block.Validator) == 0 {
+		return fmt.Errorf("%w, %s", blockchain.ErrInvalidBlock, "missing validator")
 	}
 
-	return pos.stakers[node], nil
+	return ranked(block, rules)
 }
 
-// Set sets the stake of a given node.
-func (pos *ProofOfStake) Set(node string, stake float64) {
-	pos.Lock()
-	defer pos.Unlock()
-
-	pos.stakers[node] = blockchain.ToCoin(stake)
+// Vote records the vote of a validator on a proposed block.
+func (pos *ProofOfStake) Vote(vote blockchain.Vote) {
+	pos.ballot.add(vote)
 }
 
-// Update updates the stake of a given node.
-func (pos *ProofOfStake) Update(node string, stake float64) error {
-	if !pos.Exists(node) {
-		return errors.ErrInvalidOperation("node does not exist")
-	}
-
-	pos.Lock()
-	defer pos.Unlock()
+// Certificate returns the votes that approve the proposed block.
+func (pos *ProofOfStake) Certificate(block blockchain.Block) []blockchain.Vote {
+	return pos.ballot.approvals(block)
+}
 
-	if 0 > pos.stakers[node].Float64()+stake {
-		return errors.ErrInvalidOperation("stake cannot be negative")
+// Certified checks whether the validators within the certificate of the block hold
+// the quorum of the total stake, from the certificate fork onwards.
+func (pos *ProofOfStake) Certified(block blockchain.Block, stakes map[string]blockchain.Coin, quorum int, rules blockchain.Rules) error {
+	if rules.Active(blockchain.CertificateFork) && !reached(block.Certificate, weigh(stakes), quorum) {
+		return fmt.Errorf("%w, %s", blockchain.ErrInvalidBlock, "quorum not reached")
 	}
 
-	pos.stakers[node] = pos.stakers[node].Add(stake)
-
 	return nil
 }
 
-// Add adds a node.
-func (pos *ProofOfStake) Add(node string, stake float64) error {
-	if pos.Exists(node) {
-		return errors.ErrInvalidOperation("node already exists")
-	}
+// Commit does nothing; the stakes are updated by the blockchain.
+func (pos *ProofOfStake) Commit(blockchain.Block) {}
 
-	if 0 > stake {
-		return errors.ErrInvalidOperation("stake cannot be negative")
-	}
-
-	pos.Set(node, stake)
-
-	return nil
+// Reset clears the votes on the proposal of the given slot and rank.
+func (pos *ProofOfStake) Reset(slot uint64, rank uint32) {
+	pos.ballot.clear(slot, rank)
 }
 
-// Clear clears the proof of stake.
-func (pos *ProofOfStake) Clear() {
-	pos.Lock()
-	defer pos.Unlock()
+// Punishment jails validators that equivocated, and slashes their stake.
+func (pos *ProofOfStake) Punishment() blockchain.Punishment {
+	return punishment
+}
 
-	pos.stakers = make(map[string]blockchain.Coin)
+// Downtime jails validators that stop proposing or voting.
+func (pos *ProofOfStake) Downtime() blockchain.Downtime {
+	return downtime
 }
 
-// Exists checks if the node exists.
-func (pos *ProofOfStake) Exists(node string) bool {
-	_, ok := pos.stakers[node]
+// Fees charges a base fee that tracks how full blocks are.
+func (pos *ProofOfStake) Fees() blockchain.FeeMarket {
+	return fees
+}
 
-	return ok
+// Limits limits the size and gas of the transactions of a block.
+func (pos *ProofOfStake) Limits() blockchain.Limits {
+	return limits
 }
@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/mr-tron/base58"
+)
+
+// addressVersion the version byte that prefixes every address.
+const addressVersion byte = 0x00
+
+// addressLength the length of the hash within an address.
+const addressLength = 20
+
+// checksumLength the length of the checksum of an address.
+const checksumLength = 4
+
+// ErrInvalidAddress is the error when an address is invalid.
+var ErrInvalidAddress = errors.New("invalid address")
+
+// checksum returns the checksum of the given payload; the first bytes of its double sha256 hash.
+func checksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+
+	return second[:checksumLength]
+}
+
+// NewAddress creates a base58check encoded address from the given data.
+// The address holds a version byte, the first 20 bytes of the sha256 hash of
+// the data, and a checksum such that typos can be detected.
+func NewAddress(data []byte) string {
+	hash := sha256.Sum256(data)
+
+	payload := make([]byte, 0, 1+addressLength+checksumLength)
+	payload = append(payload, addressVersion)
+	payload = append(payload, hash[:addressLength]...)
+	payload = append(payload, checksum(payload)...)
+
+	return base58.Encode(payload)
+}
+
+// Address returns the address of a public key.
+func Address(pub *ecdsa.PublicKey) string {
+	return NewAddress(EncodePublicKey(pub))
+}
+
+// ValidateAddress checks whether the address is well-formed, and whether its checksum matches.
+func ValidateAddress(address string) error {
+	payload, err := base58.Decode(address)
+	if err != nil || len(payload) != 1+addressLength+checksumLength {
+		return ErrInvalidAddress
+	}
+
+	if payload[0] != addressVersion {
+		return ErrInvalidAddress
+	}
+
+	if !bytes.Equal(checksum(payload[:1+addressLength]), payload[1+addressLength:]) {
+		return ErrInvalidAddress
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddress(t *testing.T) {
+	priv, _ := crypto.GenerateKey()
+
+	address := Address(&priv.PublicKey)
+
+	assert.NoError(t, ValidateAddress(address))
+	assert.Equal(t, address, Address(&priv.PublicKey))
+	assert.Less(t, len(address), 40)
+}
+
+func TestInvalidAddress(t *testing.T) {
+	priv, _ := crypto.GenerateKey()
+
+	address := []byte(Address(&priv.PublicKey))
+
+	// a typo should be detected by the checksum
+	if address[5] == '2' {
+		address[5] = '3'
+	} else {
+		address[5] = '2'
+	}
+
+	assert.ErrorIs(t, ValidateAddress(string(address)), ErrInvalidAddress)
+	assert.ErrorIs(t, ValidateAddress(""), ErrInvalidAddress)
+	assert.ErrorIs(t, ValidateAddress("0OIl"), ErrInvalidAddress)
+}
@@ -7,6 +7,11 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// GenerateKey generates a new private key.
+func GenerateKey() (*ecdsa.PrivateKey, error) {
+	return crypto.GenerateKey()
+}
+
 // Sign signs a hash using the private key.
 func Sign(priv *ecdsa.PrivateKey, data []byte) ([]byte, error) {
 	return crypto.Sign(crypto.Keccak256Hash(data).Bytes(), priv)
@@ -2,10 +2,14 @@ package networking
 
 // Message represents a message within the Network, whose
 // singular purpose is to be spread to all connected peers.
+// A request carries an ID, which its replies carry as well;
+// a reply carries an error if the peer failed to serve it.
 type Message struct {
+	ID      string `json:"id,omitempty"`
 	Peer    string `json:"peer"`
 	Topic   Topic  `json:"topic"`
 	Payload []byte `json:"payload"`
+	Error   string `json:"error,omitempty"`
 }
 
 // NewMessage creates a JSON encoded Message.
@@ -3,7 +3,9 @@ package networking
 import (
 	"context"
 	"fmt"
+	"io"
 	"sync"
+	"time"
 
 	"backend/util"
 
@@ -11,6 +13,7 @@ import (
 	dht "github.com/libp2p/go-libp2p-kad-dht"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/routing"
 	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
@@ -23,6 +26,9 @@ import (
 // discoveryServiceTag is used in mDNS advertisements to discover other peers.
 const discoveryServiceTag = "crypto"
 
+// replyProtocol is the protocol of the streams over which peers reply to requests.
+const replyProtocol = "/reply"
+
 // discoveryNotifee gets notified when a new peer is discovered via mDNS.
 type discoveryNotifee struct {
 	host host.Host
@@ -30,12 +36,13 @@ type discoveryNotifee struct {
 
 // Network represents a peer-to-peer network.
 type Network struct {
-	Host  host.Host
-	Subs  map[Topic]*Subscription
-	ctx   context.Context
-	ps    *pubsub.PubSub
-	wg    sync.WaitGroup
-	close chan struct{}
+	Host     host.Host
+	Subs     map[Topic]*Subscription
+	ctx      context.Context
+	ps       *pubsub.PubSub
+	requests *Requests
+	wg       sync.WaitGroup
+	close    chan struct{}
 }
 
 // NewNetwork creates a new Network with given port.
@@ -62,12 +69,13 @@ func NewNetwork(port int) (*Network, error) {
 	}
 
 	return &Network{
-		Host:  h,
-		Subs:  make(map[Topic]*Subscription, 0),
-		ctx:   ctx,
-		ps:    ps,
-		wg:    sync.WaitGroup{},
-		close: make(chan struct{}),
+		Host:     h,
+		Subs:     make(map[Topic]*Subscription, 0),
+		ctx:      ctx,
+		ps:       ps,
+		requests: NewRequests(SystemClock),
+		wg:       sync.WaitGroup{},
+		close:    make(chan struct{}),
 	}, nil
 }
 
@@ -84,6 +92,8 @@ func (n *Network) Start() error {
 		return err
 	}
 
+	n.Host.SetStreamHandler(replyProtocol, n.handleReply)
+
 	if err := n.startMdns(); err != nil {
 		return err
 	}
@@ -111,13 +121,48 @@ func (n *Network) Publish(topic Topic, payload []byte) {
 	log.Debug().Str("topic", string(topic)).Msg("network: published message")
 }
 
-// Request alias for Publish to quickly make a request on a Topic.
-func (n *Network) Request(topic Topic) {
-	n.Publish(topic, []byte("request"))
+// Ask publishes a request with the payload on the given Topic, and returns the Call
+// that awaits the replies of the connected peers until the timeout.
+func (n *Network) Ask(topic Topic, payload []byte, timeout time.Duration) *Call {
+	peers := make([]string, 0)
+
+	for _, p := range n.Host.Network().Peers() {
+		peers = append(peers, p.String())
+	}
+
+	c := n.requests.Open(topic, peers, timeout)
+
+	msg := NewMessage(n.Host.ID().String(), topic, payload)
+	msg.ID = c.ID
+
+	if err := n.Subs[topic].Publish(util.JSONEncode(msg)); err != nil {
+		log.Error().Err(err).Msg("network: failed to publish request")
+	}
+
+	log.Debug().Str("topic", string(topic)).Str("id", c.ID).Msg("network: sent request")
+
+	return c
+}
+
+// Respond replies to the peer that sent the request with the payload.
+func (n *Network) Respond(request Message, payload []byte) {
+	msg := NewMessage(n.Host.ID().String(), request.Topic, payload)
+	msg.ID = request.ID
+
+	n.reply(request.Peer, msg)
 }
 
-// Reply sends a Message to one given peer.
-func (n *Network) Reply(node string, topic Topic, payload []byte) {
+// Refuse replies to the peer that sent the request that it could not be served.
+func (n *Network) Refuse(request Message, reason error) {
+	msg := NewMessage(n.Host.ID().String(), request.Topic, nil)
+	msg.ID = request.ID
+	msg.Error = reason.Error()
+
+	n.reply(request.Peer, msg)
+}
+
+// reply sends a Message to one given peer.
+func (n *Network) reply(node string, msg Message) {
 	id, err := peer.Decode(node)
 	if err != nil {
 		log.Error().Err(err).Msg("network: failed to decode peer")
@@ -125,16 +170,14 @@ func (n *Network) Reply(node string, topic Topic, payload []byte) {
 		return
 	}
 
-	s, err := n.Host.NewStream(n.ctx, id, "/reply")
+	s, err := n.Host.NewStream(n.ctx, id, replyProtocol)
 	if err != nil {
 		log.Error().Err(err).Msg("network: failed to create stream")
 
 		return
 	}
 
-	msg := util.JSONEncode(NewMessage(n.Host.ID().String(), topic, payload))
-
-	if _, err = s.Write(msg); err != nil {
+	if _, err = s.Write(util.JSONEncode(msg)); err != nil {
 		log.Error().Err(err).Msg("network: failed to write message")
 
 		return
@@ -146,13 +189,40 @@ func (n *Network) Reply(node string, topic Topic, payload []byte) {
 		return
 	}
 
-	log.Debug().Str("topic", string(topic)).Msg("network: sent reply")
+	log.Debug().Str("topic", string(msg.Topic)).Msg("network: sent reply")
+}
+
+// handleReply passes a reply of another peer to the Call that awaits it.
+func (n *Network) handleReply(s network.Stream) {
+	var message Message
+
+	data, err := io.ReadAll(s)
+	if err != nil {
+		log.Error().Err(err).Msg("network: failed to read reply")
+
+		return
+	}
+
+	util.JSONDecode(data, &message)
+
+	// the peer is derived from the stream, as it cannot be forged
+	message.Peer = s.Conn().RemotePeer().String()
+
+	if !n.requests.Deliver(message) {
+		log.Debug().Str("topic", string(message.Topic)).Msg("network: discarded reply")
+
+		return
+	}
+
+	log.Debug().Str("topic", string(message.Topic)).Msg("network: received reply")
 }
 
 // Close closes the Network.
 func (n *Network) Close() error {
 	close(n.close)
 
+	n.Host.RemoveStreamHandler(replyProtocol)
+
 	for _, sub := range n.Subs {
 		if err := sub.Close(); err != nil {
 			return err
@@ -180,7 +250,7 @@ func (n *Network) startMdns() error {
 
 // setupSubscriptions starts and listens to all Subscriptions.
 func (n *Network) setupSubscriptions() error {
-	for _, top := range []Topic{Transaction, Block, Blockchain, Consensus, Stake, Validator} {
+	for _, top := range []Topic{Transaction, Block, Blockchain, Consensus, Evidence} {
 		sub, err := NewSubscription(n.ctx, n.ps, n.Host.ID(), top)
 		if err != nil {
 			return err
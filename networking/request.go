@@ -0,0 +1,194 @@
+package networking
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"backend/util"
+)
+
+// extraResponses is the amount of replies a Call accepts from peers it was not
+// connected to when the request was made; e.g. peers that received it through gossip.
+const extraResponses = 16
+
+var (
+	// ErrTimeout is the error of a peer that did not reply before the deadline of a request.
+	ErrTimeout = errors.New("request timed out")
+	// ErrRefused is the error of a peer that replied it failed to serve a request.
+	ErrRefused = errors.New("request refused")
+)
+
+// Response represents the reply of a single peer to a request.
+type Response struct {
+	Peer    string
+	Payload []byte
+	Err     error
+}
+
+// Clock tells the time, and runs functions at a later time; requests expire by it.
+type Clock interface {
+	Now() time.Time
+	At(t time.Time, fn func())
+}
+
+// systemClock is the Clock of the system.
+type systemClock struct{}
+
+// Now returns the current time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// At runs the function at the given time.
+func (systemClock) At(t time.Time, fn func()) {
+	time.AfterFunc(time.Until(t), fn)
+}
+
+// SystemClock is the Clock of the system.
+var SystemClock Clock = systemClock{}
+
+// Call represents a request that awaits the replies of peers. Every peer that was
+// connected when the request was made yields exactly one Response, which holds
+// ErrTimeout if the peer did not reply before the deadline. Responses is closed
+// once all of these peers replied, or once the deadline has passed; late,
+// duplicate and unsolicited replies are discarded.
+type Call struct {
+	ID        string
+	Topic     Topic
+	Deadline  time.Time
+	Responses chan Response
+	peers     map[string]bool
+	extra     int
+}
+
+// Collect blocks until the Call is done, and returns all of its Responses.
+func (c *Call) Collect() []Response {
+	responses := make([]Response, 0, len(c.peers))
+
+	for r := range c.Responses {
+		responses = append(responses, r)
+	}
+
+	return responses
+}
+
+// Requests holds the Calls that are awaiting replies, by their ID.
+type Requests struct {
+	mu    sync.Mutex
+	calls map[string]*Call
+	clock Clock
+}
+
+// NewRequests creates a new Requests, whose Calls expire by the given clock.
+func NewRequests(clock Clock) *Requests {
+	return &Requests{calls: make(map[string]*Call), clock: clock}
+}
+
+// Open creates a Call on the topic that awaits the replies of the given peers until the timeout.
+func (r *Requests) Open(topic Topic, peers []string, timeout time.Duration) *Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := &Call{
+		ID:        newID(),
+		Topic:     topic,
+		Deadline:  r.clock.Now().Add(timeout),
+		Responses: make(chan Response, len(peers)+extraResponses),
+		peers:     make(map[string]bool, len(peers)),
+	}
+
+	for _, p := range peers {
+		c.peers[p] = false
+	}
+
+	r.calls[c.ID] = c
+
+	if len(peers) == 0 {
+		r.finish(c)
+
+		return c
+	}
+
+	// a Call that is done before its deadline is no longer held, and does not expire
+	r.clock.At(c.Deadline, func() {
+		r.expire(c.ID)
+	})
+
+	return c
+}
+
+// Deliver passes the reply to the Call it belongs to; false is returned if the reply
+// was discarded.
+func (r *Requests) Deliver(message Message) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.calls[message.ID]
+	if !ok || c.Topic != message.Topic {
+		return false
+	}
+
+	replied, expected := c.peers[message.Peer]
+	if replied || (!expected && c.extra >= extraResponses) {
+		return false
+	}
+
+	if !expected {
+		c.extra++
+	}
+
+	c.peers[message.Peer] = true
+
+	response := Response{Peer: message.Peer, Payload: message.Payload}
+	if len(message.Error) > 0 {
+		response.Err = fmt.Errorf("%w: %s", ErrRefused, message.Error)
+	}
+
+	c.Responses <- response
+
+	for _, replied = range c.peers {
+		if !replied {
+			return true
+		}
+	}
+
+	r.finish(c)
+
+	return true
+}
+
+// expire ends the Call once its deadline has passed; every peer that did not reply times out.
+func (r *Requests) expire(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.calls[id]
+	if !ok {
+		return
+	}
+
+	for p, replied := range c.peers {
+		if !replied {
+			c.Responses <- Response{Peer: p, Err: ErrTimeout}
+		}
+	}
+
+	r.finish(c)
+}
+
+// finish removes the Call, and closes its Responses.
+func (r *Requests) finish(c *Call) {
+	delete(r.calls, c.ID)
+	close(c.Responses)
+}
+
+// newID creates a random ID of a request.
+func newID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return util.HexEncode(id)
+}
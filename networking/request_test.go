@@ -0,0 +1,54 @@
+package networking
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequests(t *testing.T) {
+	r := NewRequests(SystemClock)
+	c := r.Open(Blockchain, []string{"a", "b"}, time.Hour)
+
+	reply := Message{ID: c.ID, Peer: "a", Topic: Blockchain, Payload: []byte("blocks")}
+
+	assert.True(t, r.Deliver(reply))
+
+	// duplicate, unsolicited and mismatched replies are discarded
+	assert.False(t, r.Deliver(reply))
+	assert.False(t, r.Deliver(Message{ID: "unknown", Peer: "b", Topic: Blockchain}))
+	assert.False(t, r.Deliver(Message{ID: c.ID, Peer: "b", Topic: Consensus}))
+
+	// the call is done once every peer replied
+	assert.True(t, r.Deliver(Message{ID: c.ID, Peer: "b", Topic: Blockchain, Error: "no blocks"}))
+
+	responses := c.Collect()
+
+	assert.Len(t, responses, 2)
+	assert.Equal(t, Response{Peer: "a", Payload: []byte("blocks")}, responses[0])
+	assert.ErrorIs(t, responses[1].Err, ErrRefused)
+
+	// late replies are discarded
+	assert.False(t, r.Deliver(reply))
+	assert.Empty(t, r.calls)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	r := NewRequests(SystemClock)
+	c := r.Open(Consensus, []string{"a", "b"}, 10*time.Millisecond)
+
+	assert.True(t, r.Deliver(Message{ID: c.ID, Peer: "a", Topic: Consensus}))
+
+	// peers that did not reply before the deadline time out
+	responses := c.Collect()
+
+	assert.Len(t, responses, 2)
+	assert.NoError(t, responses[0].Err)
+	assert.Equal(t, "b", responses[1].Peer)
+	assert.ErrorIs(t, responses[1].Err, ErrTimeout)
+	assert.False(t, r.Deliver(Message{ID: c.ID, Peer: "b", Topic: Consensus}))
+
+	// a request without peers is done at once
+	assert.Empty(t, r.Open(Consensus, nil, time.Hour).Collect())
+}
@@ -20,8 +20,7 @@ const (
 	Block       Topic = "block"
 	Blockchain  Topic = "blockchain"
 	Consensus   Topic = "consensus"
-	Stake       Topic = "stake"
-	Validator   Topic = "validator"
+	Evidence    Topic = "evidence"
 )
 
 // Subscription represents a Subscription within the Network.
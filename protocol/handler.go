@@ -0,0 +1,135 @@
+package protocol
+
+import (
+	"fmt"
+
+	"backend/blockchain"
+	"backend/networking"
+	"backend/util"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Handle handles a message that has been published by another node.
+func (n *Node) Handle(msg networking.Message) {
+	switch msg.Topic {
+	case networking.Transaction:
+		var t blockchain.Transaction
+
+		util.JSONDecode(msg.Payload, &t)
+
+		if err := n.AddTransaction(t); err != nil {
+			log.Error().Err(err).Msg("node: failed to add transaction")
+		}
+	case networking.Block:
+		var b blockchain.Block
+
+		util.JSONDecode(msg.Payload, &b)
+
+		n.inspect(b)
+
+		// the signature of the validator and the certificate are verified by the
+		// blockchain, as the block might have been relayed by any peer
+		if err := n.blockchain.AddBlock(b); err != nil {
+			log.Error().Err(err).Msg("node: failed to add block")
+		} else {
+			n.engine.Commit(b)
+		}
+	case networking.Blockchain:
+		if n.blockchain.Len() > 0 {
+			n.network.Respond(msg, util.JSONEncode(n.blockchain))
+		} else {
+			n.network.Refuse(msg, errNoBlocks)
+		}
+	case networking.Evidence:
+		var e blockchain.Evidence
+
+		util.JSONDecode(msg.Payload, &e)
+
+		if err := n.blockchain.ReportEvidence(e); err != nil {
+			log.Debug().Err(err).Msg("node: failed to add evidence")
+		}
+	case networking.Consensus:
+		var b blockchain.Block
+
+		util.JSONDecode(msg.Payload, &b)
+
+		// a validator approves at most one block for every proposal
+		if !n.inspect(b) {
+			n.network.Refuse(msg, errEquivocated)
+
+			return
+		}
+
+		v, err := n.vote(b, n.blockchain.ValidateBlock(b) == nil)
+		if err != nil {
+			log.Error().Err(err).Msg("node: failed to sign vote")
+			n.network.Refuse(msg, err)
+
+			return
+		}
+
+		n.network.Respond(msg, util.JSONEncode(v))
+	}
+}
+
+// AddTransaction adds a transaction of another node to the memory pool.
+func (n *Node) AddTransaction(transaction blockchain.Transaction) error {
+	// check if sender exists; a validator needs no account to send the transactions
+	// of its consensus key
+	tx, err := n.blockchain.GetAccount(transaction.Sender)
+	if err != nil && (transaction.Type == blockchain.Unjail || transaction.Type == blockchain.Commitment || transaction.Type == blockchain.Reveal || transaction.Type == blockchain.Propose || transaction.Type == blockchain.Ballot) {
+		tx, err = &blockchain.Account{Balance: blockchain.ToCoin(0)}, nil
+	}
+
+	if err != nil {
+		log.Debug().Err(err).Msg("node: could not find account")
+
+		return err
+	}
+
+	// check if sender has sufficient funds; unstaking is covered by the stake instead
+	if (transaction.Type != blockchain.Unstake && transaction.Amount > tx.Balance.Float64()) || 0 > transaction.Amount {
+		log.Debug().Err(err).Msg("node: account has insufficient funds")
+
+		return fmt.Errorf("%w: insufficient funds", blockchain.ErrInvalidTransaction)
+	}
+
+	// validate signature
+	if err = transaction.Verify(); err != nil {
+		return err
+	}
+
+	// update the memory pool; this also debits the sender
+	if err = n.blockchain.UpdateMempool(transaction); err != nil {
+		log.Debug().Err(err).Msg("node: could not add transaction to mempool")
+
+		return err
+	}
+
+	log.Debug().Msg("node: added transaction")
+
+	return nil
+}
+
+// Submit adds a transaction that has been created on this node to the memory pool,
+// and publishes it to other nodes once it has been accepted.
+func (n *Node) Submit(transaction blockchain.Transaction) error {
+	// validate signature
+	if err := transaction.Verify(); err != nil {
+		log.Debug().Err(err).Msg("node: could not verify transaction")
+
+		return err
+	}
+
+	// update the memory pool; this also debits the sender
+	if err := n.blockchain.UpdateMempool(transaction); err != nil {
+		log.Debug().Err(err).Msg("node: could not add transaction to mempool")
+
+		return err
+	}
+
+	n.network.Publish(networking.Transaction, util.JSONEncode(transaction))
+
+	return nil
+}
@@ -0,0 +1,287 @@
+// Package protocol implements the protocol every validator follows: it forges the blocks
+// of the slots it is elected for, votes on the blocks proposed by others, takes part in
+// the randomness beacon, and reports validators that equivocated. The clock and the
+// network are injected, such that the same protocol runs on the wall clock and the
+// peer-to-peer network of a node, and on the virtual clock and the in-memory network
+// of a simulation.
+package protocol
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"time"
+
+	"backend/blockchain"
+	"backend/consensus"
+	"backend/crypto"
+	"backend/networking"
+	"backend/util"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// errNoBlocks is the reason a blockchain request is refused by a node without blocks.
+	errNoBlocks = errors.New("no blocks")
+	// errEquivocated is the reason a vote is refused on a block of a proposer that equivocated.
+	errEquivocated = errors.New("proposer equivocated")
+)
+
+// Network sends the messages of a Node to other nodes.
+type Network interface {
+	// Publish publishes the payload on the topic to every other node.
+	Publish(topic networking.Topic, payload []byte)
+	// Ask publishes a request, and returns the Call that awaits the replies until the timeout.
+	Ask(topic networking.Topic, payload []byte, timeout time.Duration) *networking.Call
+	// Respond replies to the node that sent the request with the payload.
+	Respond(request networking.Message, payload []byte)
+	// Refuse replies to the node that sent the request that it could not be served.
+	Refuse(request networking.Message, reason error)
+}
+
+// Node is a validator that follows the protocol. It is driven by calling Tick at the
+// start of every slot, and Handle for every message of another node.
+type Node struct {
+	key        *ecdsa.PrivateKey
+	blockchain *blockchain.Blockchain
+	engine     consensus.Engine
+	detector   *consensus.Detector
+	network    Network
+	clock      networking.Clock
+}
+
+// New creates a new Node of the validator with the given consensus key.
+func New(key *ecdsa.PrivateKey, bc *blockchain.Blockchain, engine consensus.Engine, network Network, clock networking.Clock) *Node {
+	return &Node{
+		key:        key,
+		blockchain: bc,
+		engine:     engine,
+		detector:   consensus.NewDetector(),
+		network:    network,
+		clock:      clock,
+	}
+}
+
+// Validator returns the validator of the node; the address of its consensus key.
+func (n *Node) Validator() string {
+	return crypto.Address(&n.key.PublicKey)
+}
+
+// Blockchain returns the blockchain of the node.
+func (n *Node) Blockchain() *blockchain.Blockchain {
+	return n.blockchain
+}
+
+// Tick starts the slot. The node takes part in the randomness beacon, and the proposers
+// of the slot are determined; if this node is one of them, a new block will be forged.
+// Every node derives the slots from the genesis block, such that all nodes act on the
+// same slot.
+func (n *Node) Tick(slot uint64) {
+	// votes of previous epochs are forgotten
+	if epoch := n.blockchain.Clock().Epoch; slot > epoch {
+		n.detector.Prune(slot - epoch)
+	}
+
+	n.participate(slot)
+	n.propose(slot)
+}
+
+// participate takes part in the randomness beacon once the epoch of the slot has
+// started; the secret committed to within the previous epoch is revealed, and a
+// validator commits to a new secret. Both secrets are derived from the consensus key.
+func (n *Node) participate(slot uint64) {
+	b := n.blockchain.Beacon()
+	v := n.Validator()
+
+	if b.Epoch != n.blockchain.Clock().EpochOf(slot) {
+		return
+	}
+
+	_, committed := b.Committed[v]
+	_, revealed := b.Revealed[v]
+
+	if committed && !revealed {
+		if secret, err := blockchain.NewSecret(n.key, b.Epoch-1); err == nil {
+			n.sendSecret(blockchain.Reveal, blockchain.Secret{Secret: util.HexEncode(secret)})
+		}
+	}
+
+	_, validator := n.blockchain.Validators(slot)[v]
+	_, committed = b.Commitments[v]
+
+	if validator && !committed {
+		if secret, err := blockchain.NewSecret(n.key, b.Epoch); err == nil {
+			n.sendSecret(blockchain.Commitment, blockchain.NewCommitment(secret))
+		}
+	}
+}
+
+// sendSecret creates a Commitment or Reveal transaction of the validator of this node,
+// signed by its consensus key; a validator needs no account to send it.
+func (n *Node) sendSecret(txType blockchain.TxType, s blockchain.Secret) {
+	var nonce uint64
+
+	if a, err := n.blockchain.GetAccount(n.Validator()); err == nil {
+		nonce = a.Transactions
+	}
+
+	t := blockchain.Transaction{
+		Sender:    n.Validator(),
+		Nonce:     nonce,
+		Timestamp: n.clock.Now().Unix(),
+		Type:      txType,
+		Data:      string(util.JSONEncode(s)),
+	}
+
+	if err := t.Sign(n.key); err != nil {
+		return
+	}
+
+	if err := n.Submit(t); err != nil {
+		log.Warn().Err(err).Str("type", string(txType)).Msg("node: failed to send secret")
+	}
+}
+
+// propose forges a new block if this node is one of the proposers of the slot. A backup
+// proposer waits for the window of its rank, and only forges a block if the earlier
+// ranks did not add a block in their windows; backup proposers only forge from the
+// ranks fork onwards.
+func (n *Node) propose(slot uint64) {
+	// the stakes are derived from the blockchain, and are the same on every node
+	proposers, err := n.engine.Proposers(n.blockchain.Seed(slot), slot, n.blockchain.Validators(slot))
+	if err != nil {
+		// no stakers; new block will be created by this node
+		proposers = []string{n.Validator()}
+	}
+
+	if len(proposers) > 1 && !n.blockchain.Rules(n.blockchain.Last().Height+1).Active(blockchain.RanksFork) {
+		proposers = proposers[:1]
+	}
+
+	clock := n.blockchain.Clock()
+
+	for rank, p := range proposers {
+		if p != n.Validator() {
+			continue
+		}
+
+		n.clock.At(clock.Window(slot, uint32(rank)), func() {
+			if n.blockchain.Last().Slot < slot {
+				n.forge(slot, uint32(rank))
+			}
+		})
+
+		return
+	}
+}
+
+// forge forges a new block in the given slot and rank. The votes of other validators
+// are collected during the first two thirds of the window of the rank, after which the
+// block is added along with its certificate, and published to other nodes.
+func (n *Node) forge(slot uint64, rank uint32) {
+	// create block within the budget of the builder and the limits of the chain, returns an error if there are no transactions
+	block, err := n.blockchain.CreateBlock(n.key, rank)
+	if err != nil {
+		log.Debug().Err(err).Msg("node: failed to create block")
+
+		return
+	}
+
+	clock := n.blockchain.Clock()
+	deadline := clock.Window(slot, rank).Add(clock.Timeout() * 2 / 3)
+
+	// the votes of other validators are the replies to the block
+	call := n.network.Ask(networking.Consensus, util.JSONEncode(block), deadline.Sub(n.clock.Now()))
+
+	// the validator approves its own block
+	if v, err := n.vote(block, true); err == nil {
+		n.engine.Vote(v)
+	}
+
+	n.clock.At(deadline, func() {
+		n.tally(call)
+
+		// the certificate is stored with the block, such that every node can verify
+		// whether it reaches the quorum
+		block.Certificate = n.engine.Certificate(block)
+
+		if err := n.blockchain.AddBlock(block); err != nil {
+			log.Warn().Err(err).Msg("node: failed to add block")
+		} else {
+			n.engine.Commit(block)
+			n.network.Publish(networking.Block, util.JSONEncode(block))
+		}
+
+		// only the votes of this round are cleared, as other rounds might still be collecting
+		n.engine.Reset(slot, rank)
+	})
+}
+
+// tally records the votes that have been replied to the call so far; votes that arrive
+// after the deadline are discarded.
+func (n *Node) tally(call *networking.Call) {
+	for {
+		select {
+		case r, ok := <-call.Responses:
+			if !ok {
+				return
+			}
+
+			if r.Err != nil {
+				log.Debug().Err(r.Err).Str("peer", r.Peer).Msg("node: no vote from peer")
+
+				continue
+			}
+
+			var v blockchain.Vote
+
+			util.JSONDecode(r.Payload, &v)
+
+			n.engine.Vote(v)
+			n.observe(v)
+		default:
+			return
+		}
+	}
+}
+
+// vote creates a Vote on the block, signed by the consensus key of this node.
+func (n *Node) vote(block blockchain.Block, valid bool) (blockchain.Vote, error) {
+	v := blockchain.NewVote(n.Validator(), block, valid)
+
+	if err := v.Sign(n.key); err != nil {
+		return blockchain.Vote{}, err
+	}
+
+	return v, nil
+}
+
+// observe observes the votes of other validators; see report.
+func (n *Node) observe(votes ...blockchain.Vote) {
+	n.report(n.detector.Observe(votes...))
+}
+
+// inspect observes the block proposed by another validator, and the votes of its
+// certificate; false is returned if the proposer equivocated. See report.
+func (n *Node) inspect(b blockchain.Block) bool {
+	n.observe(b.Certificate...)
+
+	evidence := n.detector.Propose(b)
+	n.report(evidence)
+
+	return len(evidence) == 0
+}
+
+// report adds the evidence of validators that equivocated to the evidence pool,
+// and publishes it to other nodes.
+func (n *Node) report(evidence []blockchain.Evidence) {
+	for _, e := range evidence {
+		if err := n.blockchain.ReportEvidence(e); err != nil {
+			continue
+		}
+
+		log.Warn().Str("validator", e.Validator()).Msg("node: validator equivocated")
+
+		n.network.Publish(networking.Evidence, util.JSONEncode(e))
+	}
+}
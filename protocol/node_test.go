@@ -0,0 +1,183 @@
+package protocol
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"backend/blockchain"
+	"backend/consensus"
+	"backend/crypto"
+	"backend/networking"
+	"backend/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clock is a manual clock; time only advances by running the scheduled functions.
+type clock struct {
+	now    time.Time
+	events []event
+}
+
+// event is a function that is scheduled at a point in time.
+type event struct {
+	at time.Time
+	fn func()
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) At(t time.Time, fn func()) {
+	c.events = append(c.events, event{at: t, fn: fn})
+}
+
+// run runs every scheduled function in order of time, including the functions they schedule.
+func (c *clock) run() {
+	for len(c.events) > 0 {
+		sort.SliceStable(c.events, func(i, j int) bool { return c.events[i].at.Before(c.events[j].at) })
+
+		e := c.events[0]
+		c.events = c.events[1:]
+
+		if e.at.After(c.now) {
+			c.now = e.at
+		}
+
+		e.fn()
+	}
+}
+
+// network records the messages sent by a node; requests are answered by no one.
+type network struct {
+	clock     *clock
+	published []networking.Topic
+	responses []networking.Message
+}
+
+func (n *network) Publish(topic networking.Topic, _ []byte) {
+	n.published = append(n.published, topic)
+}
+
+func (n *network) Ask(topic networking.Topic, _ []byte, timeout time.Duration) *networking.Call {
+	n.published = append(n.published, topic)
+
+	return networking.NewRequests(n.clock).Open(topic, nil, timeout)
+}
+
+func (n *network) Respond(request networking.Message, payload []byte) {
+	n.responses = append(n.responses, networking.Message{ID: request.ID, Topic: request.Topic, Payload: payload})
+}
+
+func (n *network) Refuse(request networking.Message, reason error) {
+	n.responses = append(n.responses, networking.Message{ID: request.ID, Topic: request.Topic, Error: reason.Error()})
+}
+
+// newNode creates a node of the development engine on the manual clock.
+func newNode(t *testing.T) (*Node, *clock, *network) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	c := &clock{now: time.Unix(1_600_000_000, 0)}
+	net := &network{clock: c}
+	engine := consensus.NewDev(crypto.Address(&key.PublicKey))
+
+	bc := blockchain.NewBlockchain()
+	bc.SetTime(c.Now)
+	bc.SetEligibility(engine.Verify)
+	require.NoError(t, bc.SetClock(time.Minute, 8))
+
+	n := New(key, bc, engine, net, c)
+	bc.Init(n.Validator())
+
+	return n, c, net
+}
+
+// transaction creates an exchange transaction from genesis.
+func transaction(t *testing.T, bc *blockchain.Blockchain) blockchain.Transaction {
+	t.Helper()
+
+	priv, pub, err := crypto.Genesis()
+	require.NoError(t, err)
+
+	genesis, err := bc.GetAccount(crypto.Address(pub))
+	require.NoError(t, err)
+
+	receiver, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	tx := blockchain.Transaction{
+		Sender:   crypto.Address(pub),
+		Receiver: crypto.Address(&receiver.PublicKey),
+		Amount:   1,
+		Nonce:    genesis.Transactions,
+		Type:     blockchain.Exchange,
+	}
+	require.NoError(t, tx.Sign(priv))
+
+	return tx
+}
+
+func TestTick(t *testing.T) {
+	n, c, net := newNode(t)
+	bc := n.Blockchain()
+
+	require.NoError(t, n.Submit(transaction(t, bc)))
+
+	// the block is forged in the window of the proposer, and added once the votes are collected
+	slot := bc.Clock().Slot(c.Now()) + 1
+
+	n.Tick(slot)
+	c.run()
+
+	assert.Equal(t, slot, bc.Last().Slot)
+	assert.Len(t, bc.Last().Transactions, 1)
+	assert.Equal(t, []networking.Topic{networking.Transaction, networking.Consensus, networking.Block}, net.published)
+
+	// the slot is not forged twice
+	n.Tick(slot)
+	c.run()
+
+	assert.Equal(t, uint64(1), bc.Last().Height)
+}
+
+func TestHandle(t *testing.T) {
+	n, _, net := newNode(t)
+	other, _, _ := newNode(t)
+
+	require.NoError(t, other.Submit(transaction(t, other.Blockchain())))
+
+	block, err := other.Blockchain().CreateBlock(other.key, 0)
+	require.NoError(t, err)
+
+	n.Handle(networking.Message{ID: "a", Topic: networking.Consensus, Payload: util.JSONEncode(block)})
+
+	// a proposer that proposes another block for the same slot and rank is refused
+	conflicting := block
+	conflicting.Timestamp++
+	require.NoError(t, conflicting.Sign(other.key))
+
+	n.Handle(networking.Message{ID: "b", Topic: networking.Consensus, Payload: util.JSONEncode(conflicting)})
+
+	require.Len(t, net.responses, 2)
+
+	var v blockchain.Vote
+
+	util.JSONDecode(net.responses[0].Payload, &v)
+
+	assert.NoError(t, v.Verify())
+	assert.Equal(t, n.Validator(), v.Validator)
+	assert.Equal(t, errEquivocated.Error(), net.responses[1].Error)
+	assert.Equal(t, []networking.Topic{networking.Evidence}, net.published)
+
+	// a node without blocks refuses to send its blockchain
+	n.Blockchain().Blocks = nil
+	n.Handle(networking.Message{ID: "c", Topic: networking.Blockchain})
+
+	assert.Equal(t, errNoBlocks.Error(), net.responses[2].Error)
+}
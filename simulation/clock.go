@@ -0,0 +1,107 @@
+package simulation
+
+import (
+	"container/heap"
+	"time"
+)
+
+// event is a function that is scheduled at a point in virtual time.
+type event struct {
+	at  time.Time
+	seq uint64
+	fn  func()
+}
+
+// queue orders events by time; events at the same time are ordered by the sequence
+// in which they have been scheduled.
+type queue []*event
+
+func (q queue) Len() int { return len(q) }
+
+func (q queue) Less(i, j int) bool {
+	if !q[i].at.Equal(q[j].at) {
+		return q[i].at.Before(q[j].at)
+	}
+
+	return q[i].seq < q[j].seq
+}
+
+func (q queue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *queue) Push(x any) { *q = append(*q, x.(*event)) }
+
+func (q *queue) Pop() any {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return e
+}
+
+// Clock is a virtual clock; time only advances by running the scheduled events in
+// order, such that a simulation does not depend on the wall clock or the scheduler.
+type Clock struct {
+	now    time.Time
+	seq    uint64
+	events queue
+}
+
+// NewClock creates a new Clock that starts at the given time.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the current virtual time.
+func (c *Clock) Now() time.Time {
+	return c.now
+}
+
+// At schedules the function at the given time; a time in the past is treated as now.
+func (c *Clock) At(t time.Time, fn func()) {
+	if t.Before(c.now) {
+		t = c.now
+	}
+
+	c.seq++
+	heap.Push(&c.events, &event{at: t, seq: c.seq, fn: fn})
+}
+
+// AfterFunc schedules the function after the given duration.
+func (c *Clock) AfterFunc(d time.Duration, fn func()) {
+	c.At(c.now.Add(d), fn)
+}
+
+// RunUntil runs every event that is scheduled up to and including the given time,
+// including the events that are scheduled by them, and advances the clock to that time.
+func (c *Clock) RunUntil(t time.Time) {
+	for len(c.events) > 0 && !c.events[0].at.After(t) {
+		e := heap.Pop(&c.events).(*event)
+
+		c.now = e.at
+		e.fn()
+	}
+
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+// nodeClock is the virtual clock as seen by a node; the events of a crashed node are dropped.
+type nodeClock struct {
+	clock *Clock
+	node  *Node
+}
+
+// Now returns the current virtual time.
+func (c nodeClock) Now() time.Time {
+	return c.clock.Now()
+}
+
+// At schedules the function at the given time; it is not run if the node crashed by then.
+func (c nodeClock) At(t time.Time, fn func()) {
+	c.clock.At(t, func() {
+		if !c.node.crashed {
+			fn()
+		}
+	})
+}
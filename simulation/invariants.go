@@ -0,0 +1,41 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+
+	"backend/util"
+)
+
+// ErrInvariant is the base error when an invariant of the simulation does not hold.
+var ErrInvariant = errors.New("invariant violated")
+
+// Check checks the invariants of the network: no two nodes have finalized conflicting
+// blocks, and the funds on every node, including the burned funds, amount to the funds
+// created at genesis.
+func (s *Simulation) Check() error {
+	for i, n := range s.nodes {
+		circulating, burned := n.Blockchain().Supply()
+
+		if total := circulating.Add(burned.Float64()); !total.Equal(s.supply) {
+			return fmt.Errorf("%w: node %d holds %s instead of %s", ErrInvariant, i, total, s.supply)
+		}
+
+		_, finalized := n.Blockchain().Finality()
+
+		for j, other := range s.nodes {
+			_, f := other.Blockchain().Finality()
+
+			// the blocks up to the finalized checkpoint of the other node are final as well
+			if f.Height < finalized.Height {
+				continue
+			}
+
+			if hash := util.HexEncode(other.Blockchain().Blocks[finalized.Height].Hash()); hash != finalized.Hash {
+				return fmt.Errorf("%w: nodes %d and %d finalized conflicting blocks at height %d", ErrInvariant, i, j, finalized.Height)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package simulation
+
+import (
+	"math/rand"
+	"time"
+
+	"backend/networking"
+)
+
+// Network is an in-memory network between the nodes of a simulation. Every message is
+// delivered after the latency of the network, a random jitter and the delay of both
+// nodes, unless the nodes are partitioned, or the receiver has crashed.
+type Network struct {
+	clock     *Clock
+	rand      *rand.Rand
+	nodes     []*Node
+	latency   time.Duration
+	jitter    time.Duration
+	delays    map[int]time.Duration
+	partition map[int]int
+}
+
+// newNetwork creates a new Network.
+func newNetwork(clock *Clock, r *rand.Rand, latency time.Duration, jitter time.Duration) *Network {
+	return &Network{
+		clock:   clock,
+		rand:    r,
+		latency: latency,
+		jitter:  jitter,
+		delays:  make(map[int]time.Duration),
+	}
+}
+
+// Partition splits the network into the given groups of nodes; messages are only
+// delivered between nodes of the same group. Nodes without a group are isolated.
+func (n *Network) Partition(groups ...[]int) {
+	n.partition = make(map[int]int)
+
+	for g, nodes := range groups {
+		for _, i := range nodes {
+			n.partition[i] = g
+		}
+	}
+}
+
+// Heal removes the partition of the network.
+func (n *Network) Heal() {
+	n.partition = nil
+}
+
+// Delay delays every message that is sent to or by the node.
+func (n *Network) Delay(node int, d time.Duration) {
+	n.delays[node] = d
+}
+
+// connected checks whether messages can be delivered between the nodes.
+func (n *Network) connected(from int, to int) bool {
+	if n.partition == nil {
+		return true
+	}
+
+	a, ok := n.partition[from]
+	if !ok {
+		return false
+	}
+
+	b, ok := n.partition[to]
+
+	return ok && a == b
+}
+
+// deliver delivers the message to the given node, by passing it to the handler.
+func (n *Network) deliver(from int, to int, msg networking.Message, handle func(node *Node, msg networking.Message)) {
+	if !n.connected(from, to) {
+		return
+	}
+
+	d := n.latency + n.delays[from] + n.delays[to]
+
+	if n.jitter > 0 {
+		d += time.Duration(n.rand.Int63n(int64(n.jitter)))
+	}
+
+	n.clock.AfterFunc(d, func() {
+		if node := n.nodes[to]; !node.crashed {
+			handle(node, msg)
+		}
+	})
+}
+
+// publish sends the message to every other node, whose protocol handles it.
+func (n *Network) publish(from int, msg networking.Message) {
+	for to := range n.nodes {
+		if to != from {
+			n.deliver(from, to, msg, func(node *Node, msg networking.Message) {
+				node.protocol.Handle(msg)
+			})
+		}
+	}
+}
+
+// reply sends the reply to the node of the given validator, which passes it to the
+// Call that awaits it.
+func (n *Network) reply(from int, to string, msg networking.Message) {
+	for i, node := range n.nodes {
+		if node.Validator() == to {
+			n.deliver(from, i, msg, func(node *Node, msg networking.Message) {
+				node.endpoint.requests.Deliver(msg)
+			})
+		}
+	}
+}
+
+// endpoint connects a node to the Network; it is the network of the protocol of the
+// node, on which the node is identified by its validator.
+type endpoint struct {
+	network  *Network
+	node     *Node
+	requests *networking.Requests
+}
+
+// newEndpoint creates a new endpoint of the node; its requests expire by the virtual clock.
+func newEndpoint(network *Network, node *Node) *endpoint {
+	return &endpoint{network: network, node: node, requests: networking.NewRequests(network.clock)}
+}
+
+// Publish publishes the payload on the topic to every other node.
+func (e *endpoint) Publish(topic networking.Topic, payload []byte) {
+	e.network.publish(e.node.index, networking.NewMessage(e.node.Validator(), topic, payload))
+}
+
+// Ask publishes a request to every other node, and returns the Call that awaits their
+// replies until the timeout. A byzantine node proposes a conflicting block along with
+// every block it asks the other nodes to vote on.
+func (e *endpoint) Ask(topic networking.Topic, payload []byte, timeout time.Duration) *networking.Call {
+	peers := make([]string, 0, len(e.network.nodes)-1)
+
+	for _, node := range e.network.nodes {
+		if node != e.node {
+			peers = append(peers, node.Validator())
+		}
+	}
+
+	c := e.requests.Open(topic, peers, timeout)
+
+	msg := networking.NewMessage(e.node.Validator(), topic, payload)
+	msg.ID = c.ID
+
+	e.network.publish(e.node.index, msg)
+
+	// the votes on the conflicting block are discarded, as no Call awaits them
+	if e.node.byzantine && topic == networking.Consensus {
+		e.network.publish(e.node.index, networking.NewMessage(e.node.Validator(), topic, e.node.conflict(payload)))
+	}
+
+	return c
+}
+
+// Respond replies to the node that sent the request with the payload.
+func (e *endpoint) Respond(request networking.Message, payload []byte) {
+	msg := networking.NewMessage(e.node.Validator(), request.Topic, payload)
+	msg.ID = request.ID
+
+	e.network.reply(e.node.index, request.Peer, msg)
+}
+
+// Refuse replies to the node that sent the request that it could not be served.
+func (e *endpoint) Refuse(request networking.Message, reason error) {
+	msg := networking.NewMessage(e.node.Validator(), request.Topic, nil)
+	msg.ID = request.ID
+	msg.Error = reason.Error()
+
+	e.network.reply(e.node.index, request.Peer, msg)
+}
@@ -0,0 +1,66 @@
+package simulation
+
+import (
+	"crypto/ecdsa"
+
+	"backend/blockchain"
+	"backend/consensus"
+	"backend/crypto"
+	"backend/protocol"
+	"backend/util"
+)
+
+// Node is a validator within a simulation. It runs the protocol of the node of the
+// command, on the virtual clock and the in-memory network. A crashed node no longer
+// acts; a byzantine node proposes a conflicting block along with every block it forges.
+type Node struct {
+	index     int
+	key       *ecdsa.PrivateKey
+	engine    consensus.Engine
+	protocol  *protocol.Node
+	endpoint  *endpoint
+	crashed   bool
+	byzantine bool
+}
+
+// Validator returns the validator of the node; the address of its consensus key.
+func (n *Node) Validator() string {
+	return crypto.Address(&n.key.PublicKey)
+}
+
+// Blockchain returns the blockchain of the node.
+func (n *Node) Blockchain() *blockchain.Blockchain {
+	return n.protocol.Blockchain()
+}
+
+// tick starts the slot, unless the node crashed.
+func (n *Node) tick(slot uint64) {
+	if !n.crashed {
+		n.protocol.Tick(slot)
+	}
+}
+
+// vote creates a Vote on the block, signed by the consensus key of this node.
+func (n *Node) vote(block blockchain.Block, valid bool) blockchain.Vote {
+	v := blockchain.NewVote(n.Validator(), block, valid)
+
+	// signing only fails on an invalid key
+	_ = v.Sign(n.key)
+
+	return v
+}
+
+// conflict returns a block that conflicts with the proposed block; it is signed by this
+// node for the same slot and rank, but has another hash.
+func (n *Node) conflict(payload []byte) []byte {
+	var b blockchain.Block
+
+	util.JSONDecode(payload, &b)
+
+	b.Timestamp++
+
+	// signing only fails on an invalid key
+	_ = b.Sign(n.key)
+
+	return util.JSONEncode(b)
+}
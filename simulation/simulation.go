@@ -0,0 +1,371 @@
+package simulation
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"backend/blockchain"
+	"backend/consensus"
+	"backend/crypto"
+	"backend/errors"
+	"backend/protocol"
+	"backend/util"
+)
+
+// genesisTime is the virtual time at which every simulation starts.
+var genesisTime = time.Unix(1_600_000_000, 0)
+
+// Config holds the configuration of a Simulation.
+type Config struct {
+	// Seed determines every random choice; a simulation is reproduced by its seed.
+	Seed int64
+	// Validators is the amount of nodes; every node is a validator.
+	Validators int
+	// Accounts is the amount of accounts that send transactions to each other.
+	Accounts int
+	// Engine is the name of the consensus engine; either "pos" or "poa".
+	Engine string
+	// Quorum is the percentage of the total weight that should approve a block.
+	Quorum int
+	// Stake is the stake that is bonded to every validator at genesis.
+	Stake float64
+	// Slot is the duration of a slot, and Epoch the amount of slots within an epoch.
+	Slot  time.Duration
+	Epoch uint64
+	// Latency is the time it takes to deliver a message, with a random jitter on top.
+	Latency time.Duration
+	Jitter  time.Duration
+}
+
+// DefaultConfig returns the configuration of a proof of stake network of four
+// validators, with the given seed.
+func DefaultConfig(seed int64) Config {
+	return Config{
+		Seed:       seed,
+		Validators: 4,
+		Accounts:   8,
+		Engine:     "pos",
+		Quorum:     67,
+		Stake:      100,
+		Slot:       6 * time.Second,
+		Epoch:      8,
+		Latency:    100 * time.Millisecond,
+		Jitter:     200 * time.Millisecond,
+	}
+}
+
+// account is an account that sends transactions within a simulation.
+type account struct {
+	priv    *ecdsa.PrivateKey
+	address string
+}
+
+// Simulation runs a network of validators within a single process, on a virtual
+// clock and an in-memory network. Faults can be scripted at any slot, and the
+// invariants of the network are checked after every slot.
+type Simulation struct {
+	config   Config
+	clock    *Clock
+	rand     *rand.Rand
+	network  *Network
+	nodes    []*Node
+	accounts []account
+	supply   blockchain.Coin
+	slot     uint64
+}
+
+// New creates a new Simulation. Every node starts from the same genesis block, which
+// is followed by a block that bonds the stake of every validator and funds every
+// account. The simulation starts once these stakes have taken effect.
+func New(config Config) (*Simulation, error) {
+	if config.Validators <= 0 || config.Accounts < 2 {
+		return nil, errors.ErrInvalidArgument("simulation needs validators, and at least two accounts")
+	}
+
+	r := rand.New(rand.NewSource(config.Seed))
+	clock := NewClock(genesisTime)
+
+	s := &Simulation{
+		config:  config,
+		clock:   clock,
+		rand:    r,
+		network: newNetwork(clock, r, config.Latency, config.Jitter),
+	}
+
+	keys := make([]*ecdsa.PrivateKey, config.Validators)
+	validators := make([]string, config.Validators)
+
+	for i := range keys {
+		keys[i] = newKey(r)
+		validators[i] = crypto.Address(&keys[i].PublicKey)
+	}
+
+	for i, key := range keys {
+		node, err := s.newNode(i, key, validators)
+		if err != nil {
+			return nil, err
+		}
+
+		s.nodes = append(s.nodes, node)
+	}
+
+	s.network.nodes = s.nodes
+
+	for i := 0; i < config.Accounts; i++ {
+		priv := newKey(r)
+
+		s.accounts = append(s.accounts, account{priv: priv, address: crypto.Address(&priv.PublicKey)})
+	}
+
+	if err := s.bootstrap(keys); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// newKey derives a private key from the random source, such that a simulation creates
+// the same keys for the same seed.
+func newKey(r *rand.Rand) *ecdsa.PrivateKey {
+	for {
+		b := make([]byte, 32)
+
+		// reading from a rand.Rand never fails
+		_, _ = r.Read(b)
+
+		if key, err := crypto.DecodePrivateKey(b); err == nil {
+			return key
+		}
+	}
+}
+
+// newNode creates the node of the validator with the given key.
+func (s *Simulation) newNode(index int, key *ecdsa.PrivateKey, validators []string) (*Node, error) {
+	engine, err := consensus.New(consensus.Config{
+		Engine:      s.config.Engine,
+		ID:          crypto.Address(&key.PublicKey),
+		Authorities: validators,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bc := blockchain.NewBlockchain()
+
+	bc.SetTime(s.clock.Now)
+	bc.SetEligibility(engine.Verify)
+	bc.SetElection(engine.Proposers)
+	bc.SetCertification(engine.Certified)
+	bc.SetPunishment(engine.Punishment())
+	bc.SetDowntime(engine.Downtime())
+	bc.SetFeeMarket(engine.Fees())
+	bc.SetLimits(engine.Limits())
+
+	if err = bc.SetClock(s.config.Slot, s.config.Epoch); err != nil {
+		return nil, err
+	}
+
+	if err = bc.SetQuorum(s.config.Quorum); err != nil {
+		return nil, err
+	}
+
+	n := &Node{index: index, key: key, engine: engine}
+	n.endpoint = newEndpoint(s.network, n)
+	n.protocol = protocol.New(key, bc, engine, n.endpoint, nodeClock{clock: s.clock, node: n})
+
+	return n, nil
+}
+
+// bootstrap creates the genesis block, and the block that bonds the stakes and funds
+// the accounts in the first slot; every node is initialized with both blocks.
+func (s *Simulation) bootstrap(keys []*ecdsa.PrivateKey) error {
+	bc := s.nodes[0].Blockchain()
+	bc.Init(s.nodes[0].Validator())
+
+	s.supply, _ = bc.Supply()
+	s.clock.RunUntil(bc.Clock().Start(1))
+
+	priv, pub, err := crypto.Genesis()
+	if err != nil {
+		return err
+	}
+
+	genesis, err := bc.GetAccount(crypto.Address(pub))
+	if err != nil {
+		return err
+	}
+
+	nonce := genesis.Transactions
+
+	for _, key := range keys {
+		bond := blockchain.Bond{
+			Validator: crypto.Address(&key.PublicKey),
+			Key:       util.HexEncode(crypto.EncodePublicKey(&key.PublicKey)),
+		}
+
+		if err = bc.UpdateMempool(s.transaction(priv, "", s.config.Stake, nonce, blockchain.Stake, bond)); err != nil {
+			return err
+		}
+
+		nonce++
+	}
+
+	for _, a := range s.accounts {
+		if err = bc.UpdateMempool(s.transaction(priv, a.address, 1000, nonce, blockchain.Exchange, nil)); err != nil {
+			return err
+		}
+
+		nonce++
+	}
+
+	// the block is forged by the proposer of the first slot, or by anyone when no one is elected
+	forger := keys[0]
+
+	if proposers, err := s.nodes[0].engine.Proposers(bc.Seed(1), 1, bc.Validators(1)); err == nil {
+		for i, n := range s.nodes {
+			if n.Validator() == proposers[0] {
+				forger = keys[i]
+			}
+		}
+	}
+
+	block, err := bc.CreateBlock(forger, 0)
+	if err != nil {
+		return err
+	}
+
+	// every validator approves the block; the quorum is only needed once validators are elected
+	for _, n := range s.nodes {
+		block.Certificate = append(block.Certificate, n.vote(block, true))
+	}
+
+	if err = bc.AddBlock(block); err != nil {
+		return err
+	}
+
+	for _, n := range s.nodes[1:] {
+		n.Blockchain().Init(n.Validator(), append([]blockchain.Block{}, bc.Blocks...))
+	}
+
+	// the stakes take effect in the next epoch
+	clock := bc.Clock()
+	s.slot = (clock.EpochOf(1) + 1) * clock.Epoch
+
+	return nil
+}
+
+// transaction creates a transaction of the given sender; data is encoded as JSON.
+func (s *Simulation) transaction(priv *ecdsa.PrivateKey, receiver string, amount float64, nonce uint64, txType blockchain.TxType, data any) blockchain.Transaction {
+	t := blockchain.Transaction{
+		Sender:    crypto.Address(&priv.PublicKey),
+		Receiver:  receiver,
+		Amount:    amount,
+		Nonce:     nonce,
+		Timestamp: s.clock.Now().Unix(),
+		Type:      txType,
+	}
+
+	if data != nil {
+		t.Data = string(util.JSONEncode(data))
+	}
+
+	// signing only fails on an invalid key
+	_ = t.Sign(priv)
+
+	return t
+}
+
+// Nodes returns the nodes of the simulation.
+func (s *Simulation) Nodes() []*Node {
+	return s.nodes
+}
+
+// Slot returns the next slot the simulation will run.
+func (s *Simulation) Slot() uint64 {
+	return s.slot
+}
+
+// At schedules the function at the start of the given slot, before the nodes act on
+// the slot. It is used to script faults.
+func (s *Simulation) At(slot uint64, fn func()) {
+	s.clock.At(s.nodes[0].Blockchain().Clock().Start(slot), fn)
+}
+
+// Partition splits the network into the given groups of nodes; see Network.Partition.
+func (s *Simulation) Partition(groups ...[]int) {
+	s.network.Partition(groups...)
+}
+
+// Heal removes the partition of the network.
+func (s *Simulation) Heal() {
+	s.network.Heal()
+}
+
+// Delay delays every message that is sent to or by the node.
+func (s *Simulation) Delay(node int, d time.Duration) {
+	s.network.Delay(node, d)
+}
+
+// Crash crashes the node; it no longer sends or receives messages, nor forges blocks.
+func (s *Simulation) Crash(node int) {
+	s.nodes[node].crashed = true
+}
+
+// Byzantine makes the node byzantine; it proposes a conflicting block along with every
+// block it forges.
+func (s *Simulation) Byzantine(node int) {
+	s.nodes[node].byzantine = true
+}
+
+// Run runs the given amount of slots. At the start of every slot, every account might
+// send a transaction through a random node. The invariants are checked at the end of
+// every slot; the first violation is returned.
+func (s *Simulation) Run(slots uint64) error {
+	for end := s.slot + slots; s.slot < end; s.slot++ {
+		slot := s.slot
+		clock := s.nodes[0].Blockchain().Clock()
+
+		s.clock.At(clock.Start(slot), func() {
+			s.transact()
+
+			for _, n := range s.nodes {
+				n.tick(slot)
+			}
+		})
+
+		s.clock.RunUntil(clock.Start(slot + 1).Add(-time.Nanosecond))
+
+		if err := s.Check(); err != nil {
+			return fmt.Errorf("slot %d: %w", slot, err)
+		}
+	}
+
+	return nil
+}
+
+// transact lets every account send a transaction to another account with a chance of
+// one in two. Transactions sent to a crashed node are lost.
+func (s *Simulation) transact() {
+	for _, a := range s.accounts {
+		if s.rand.Intn(2) == 0 {
+			continue
+		}
+
+		receiver := s.accounts[s.rand.Intn(len(s.accounts))]
+		node := s.nodes[s.rand.Intn(len(s.nodes))]
+		amount := float64(1 + s.rand.Intn(10))
+
+		if receiver.address == a.address || node.crashed {
+			continue
+		}
+
+		sender, err := node.Blockchain().GetAccount(a.address)
+		if err != nil {
+			continue
+		}
+
+		_ = node.protocol.Submit(s.transaction(a.priv, receiver.address, amount, sender.Transactions, blockchain.Exchange, nil))
+	}
+}
@@ -0,0 +1,170 @@
+package simulation
+
+import (
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"backend/blockchain"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seed is the seed of every simulation; a failing simulation is reproduced by its seed.
+var seed = flag.Int64("seed", 1, "seed of the simulations")
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	// every node logs every transaction and block it rejects
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+
+	os.Exit(m.Run())
+}
+
+// simulate runs the given amount of slots of a new simulation, after the script has
+// scheduled its faults.
+func simulate(t *testing.T, config Config, slots uint64, script func(s *Simulation)) *Simulation {
+	t.Helper()
+
+	s, err := New(config)
+	require.NoError(t, err)
+
+	if script != nil {
+		script(s)
+	}
+
+	require.NoError(t, s.Run(slots), "seed %d", config.Seed)
+
+	return s
+}
+
+// last returns the last block of the node.
+func last(s *Simulation, node int) blockchain.Block {
+	return s.Nodes()[node].Blockchain().Last()
+}
+
+func TestSimulation(t *testing.T) {
+	for _, engine := range []string{"pos", "poa"} {
+		config := DefaultConfig(*seed)
+		config.Engine = engine
+
+		s := simulate(t, config, 48, nil)
+
+		for i := range s.Nodes() {
+			assert.Equal(t, last(s, 0), last(s, i), engine)
+		}
+
+		// a block is added in nearly every slot, such that checkpoints are finalized
+		_, finalized := s.Nodes()[0].Blockchain().Finality()
+
+		assert.Greater(t, last(s, 0).Height, uint64(40), engine)
+		assert.Greater(t, finalized.Height, uint64(0), engine)
+	}
+}
+
+func TestBeacon(t *testing.T) {
+	s := simulate(t, DefaultConfig(*seed), 32, nil)
+	bc := s.Nodes()[0].Blockchain()
+
+	reveals := 0
+
+	for _, b := range bc.Blocks {
+		for _, tx := range b.Transactions {
+			if tx.Type == blockchain.Reveal {
+				reveals++
+			}
+		}
+	}
+
+	assert.GreaterOrEqual(t, reveals, 2*len(s.Nodes()))
+
+	// every validator reveals its secret, and is therefore not slashed
+	for v, stake := range bc.Validators(last(s, 0).Slot + 1) {
+		assert.True(t, stake.Equal(blockchain.ToCoin(s.config.Stake)), v)
+	}
+
+	assert.NotEqual(t, bc.Blocks[2].Beacon, last(s, 0).Beacon)
+}
+
+func TestReproducible(t *testing.T) {
+	a := simulate(t, DefaultConfig(*seed), 16, nil)
+	b := simulate(t, DefaultConfig(*seed), 16, nil)
+
+	for i := range a.Nodes() {
+		assert.Equal(t, a.Nodes()[i].Blockchain().Blocks, b.Nodes()[i].Blockchain().Blocks)
+	}
+
+	c := simulate(t, DefaultConfig(*seed+1), 16, nil)
+
+	assert.NotEqual(t, last(a, 0).Hash(), last(c, 0).Hash())
+}
+
+func TestPartition(t *testing.T) {
+	var before, during blockchain.Block
+
+	s := simulate(t, DefaultConfig(*seed), 48, func(s *Simulation) {
+		start := s.Slot()
+
+		s.At(start+8, func() {
+			before = last(s, 0)
+
+			s.Partition([]int{0, 1, 2}, []int{3})
+		})
+
+		s.At(start+24, func() {
+			during = last(s, 0)
+
+			s.Heal()
+		})
+	})
+
+	// the majority holds the quorum, and keeps adding blocks; the minority stalls
+	assert.Greater(t, during.Height, before.Height+8)
+	assert.Greater(t, last(s, 0).Height, during.Height)
+	assert.LessOrEqual(t, last(s, 3).Height, before.Height+1)
+}
+
+func TestCrash(t *testing.T) {
+	s := simulate(t, DefaultConfig(*seed), 32, func(s *Simulation) {
+		s.Crash(3)
+		s.Delay(2, 200*time.Millisecond)
+	})
+
+	// the slots of the crashed validator are taken over by backup proposers
+	assert.Greater(t, last(s, 0).Height, uint64(24))
+	assert.Equal(t, last(s, 0), last(s, 2))
+
+	// the crashed validator misses its votes, and is jailed
+	bc := s.Nodes()[0].Blockchain()
+	validator := s.Nodes()[3].Validator()
+
+	assert.True(t, bc.Liveness()[validator].Jailed)
+	assert.NotContains(t, bc.Validators(s.Slot()), validator)
+}
+
+func TestByzantine(t *testing.T) {
+	s := simulate(t, DefaultConfig(*seed), 32, func(s *Simulation) {
+		s.Byzantine(3)
+	})
+
+	bc := s.Nodes()[0].Blockchain()
+	validator := s.Nodes()[3].Validator()
+
+	// the equivocating validator is jailed, and its stake is slashed
+	_, burned := bc.Supply()
+
+	assert.False(t, burned.Equal(blockchain.ToCoin(0)))
+	assert.NotContains(t, bc.Validators(s.Slot()), validator)
+}
+
+func TestInvariants(t *testing.T) {
+	s := simulate(t, DefaultConfig(*seed), 1, nil)
+
+	s.supply = s.supply.Add(1)
+
+	assert.ErrorIs(t, s.Check(), ErrInvariant)
+}
@@ -0,0 +1,65 @@
+package vm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Assemble assembles the given source into code. Every instruction is written on its
+// own line by name; PUSH takes a single integer argument. Anything after a semicolon
+// is seen as a comment.
+//
+//	PUSH 1  ; key
+//	PUSH 42 ; value
+//	STORE
+func Assemble(source string) ([]byte, error) {
+	names := make(map[string]Opcode, len(instructions))
+
+	for op, in := range instructions {
+		names[in.name] = op
+	}
+
+	code := make([]byte, 0, len(source))
+
+	for n, line := range strings.Split(source, "\n") {
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(strings.ToUpper(line))
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		op, ok := names[fields[0]]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown instruction %q on line %d", errExecution, fields[0], n+1)
+		}
+
+		code = append(code, byte(op))
+
+		if op != PUSH {
+			if len(fields) != 1 {
+				return nil, fmt.Errorf("%w: unexpected argument on line %d", errExecution, n+1)
+			}
+
+			continue
+		}
+
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: missing argument on line %d", errExecution, n+1)
+		}
+
+		v, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid argument on line %d", errExecution, n+1)
+		}
+
+		code = binary.BigEndian.AppendUint64(code, uint64(v))
+	}
+
+	return code, nil
+}
@@ -0,0 +1,78 @@
+package vm
+
+// Opcode is a singular instruction of the virtual machine.
+type Opcode byte
+
+const (
+	STOP Opcode = iota
+	PUSH
+	POP
+	DUP
+	SWAP
+	ADD
+	SUB
+	MUL
+	DIV
+	MOD
+	LT
+	GT
+	EQ
+	ISZERO
+	AND
+	OR
+	JUMP
+	JUMPI
+	LOAD
+	STORE
+	INPUT
+	VALUE
+	HEIGHT
+	RETURN
+	REVERT
+)
+
+// instruction holds the properties of an Opcode.
+type instruction struct {
+	name string
+	gas  uint64
+	// pops is the amount of values the instruction takes from the stack.
+	pops int
+}
+
+// instructions holds the properties of every Opcode.
+var instructions = map[Opcode]instruction{
+	STOP:   {"STOP", 0, 0},
+	PUSH:   {"PUSH", 1, 0},
+	POP:    {"POP", 1, 1},
+	DUP:    {"DUP", 1, 1},
+	SWAP:   {"SWAP", 1, 2},
+	ADD:    {"ADD", 2, 2},
+	SUB:    {"SUB", 2, 2},
+	MUL:    {"MUL", 3, 2},
+	DIV:    {"DIV", 3, 2},
+	MOD:    {"MOD", 3, 2},
+	LT:     {"LT", 2, 2},
+	GT:     {"GT", 2, 2},
+	EQ:     {"EQ", 2, 2},
+	ISZERO: {"ISZERO", 2, 1},
+	AND:    {"AND", 2, 2},
+	OR:     {"OR", 2, 2},
+	JUMP:   {"JUMP", 4, 1},
+	JUMPI:  {"JUMPI", 5, 2},
+	LOAD:   {"LOAD", 20, 1},
+	STORE:  {"STORE", 100, 2},
+	INPUT:  {"INPUT", 2, 1},
+	VALUE:  {"VALUE", 1, 0},
+	HEIGHT: {"HEIGHT", 1, 0},
+	RETURN: {"RETURN", 0, 1},
+	REVERT: {"REVERT", 0, 0},
+}
+
+// String returns the name of the Opcode.
+func (op Opcode) String() string {
+	if i, ok := instructions[op]; ok {
+		return i.name
+	}
+
+	return "INVALID"
+}
@@ -0,0 +1,251 @@
+package vm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// This is a small stack based virtual machine. Every value is a signed 64-bit integer,
+// and every instruction has a fixed gas cost; the execution is deterministic, such that
+// every node computes the same result for the same contract.
+
+// maxStack the maximum size of the stack.
+const maxStack = 1024
+
+var (
+	// ErrOutOfGas is returned when the execution exceeds its gas limit.
+	ErrOutOfGas = errors.New("out of gas")
+	// ErrReverted is returned when the contract reverts the execution.
+	ErrReverted = errors.New("execution reverted")
+	// errExecution is the base error when the execution fails.
+	errExecution = errors.New("execution failed")
+)
+
+// Storage is the key/value storage of a contract.
+type Storage interface {
+	Get(key int64) int64
+	Set(key int64, value int64)
+}
+
+// Context holds the environment in which a contract is executed.
+type Context struct {
+	Input  []int64
+	Value  int64
+	Height uint64
+	Gas    uint64
+}
+
+// Result holds the result of an execution.
+type Result struct {
+	Return  int64  `json:"return"`
+	GasUsed uint64 `json:"gasUsed"`
+}
+
+// machine holds the state of a singular execution.
+type machine struct {
+	code    []byte
+	ctx     Context
+	storage Storage
+	stack   []int64
+	pc      int
+	gas     uint64
+}
+
+// Execute executes the code within the given context. Changes to the storage are not
+// reverted on error; the caller is responsible for discarding them.
+func Execute(code []byte, ctx Context, storage Storage) (Result, error) {
+	m := &machine{
+		code:    code,
+		ctx:     ctx,
+		storage: storage,
+		stack:   make([]int64, 0, 16),
+	}
+
+	ret, err := m.run()
+
+	return Result{Return: ret, GasUsed: m.gas}, err
+}
+
+// Validate checks whether the code only holds valid instructions.
+func Validate(code []byte) error {
+	if len(code) == 0 {
+		return fmt.Errorf("%w: empty code", errExecution)
+	}
+
+	for pc := 0; pc < len(code); pc++ {
+		op := Opcode(code[pc])
+
+		if _, ok := instructions[op]; !ok {
+			return fmt.Errorf("%w: invalid opcode %d at %d", errExecution, op, pc)
+		}
+
+		if op == PUSH {
+			if pc+8 >= len(code) {
+				return fmt.Errorf("%w: incomplete push at %d", errExecution, pc)
+			}
+
+			pc += 8
+		}
+	}
+
+	return nil
+}
+
+// pop pops a value from the stack.
+func (m *machine) pop() int64 {
+	v := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+
+	return v
+}
+
+// push pushes a value to the stack.
+func (m *machine) push(v int64) error {
+	if len(m.stack) >= maxStack {
+		return fmt.Errorf("%w: stack overflow", errExecution)
+	}
+
+	m.stack = append(m.stack, v)
+
+	return nil
+}
+
+// bool converts a bool to a value.
+func bool64(b bool) int64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// jump moves the program counter to the given destination.
+func (m *machine) jump(dest int64) error {
+	if 0 > dest || dest >= int64(len(m.code)) {
+		return fmt.Errorf("%w: invalid jump to %d", errExecution, dest)
+	}
+
+	m.pc = int(dest)
+
+	return nil
+}
+
+// run runs the code until it stops, returns, or fails.
+func (m *machine) run() (int64, error) {
+	for m.pc < len(m.code) {
+		op := Opcode(m.code[m.pc])
+
+		in, ok := instructions[op]
+		if !ok {
+			return 0, fmt.Errorf("%w: invalid opcode %d at %d", errExecution, op, m.pc)
+		}
+
+		if m.gas+in.gas > m.ctx.Gas {
+			m.gas = m.ctx.Gas
+
+			return 0, ErrOutOfGas
+		}
+
+		m.gas += in.gas
+
+		if len(m.stack) < in.pops {
+			return 0, fmt.Errorf("%w: stack underflow at %d", errExecution, m.pc)
+		}
+
+		m.pc++
+
+		var err error
+
+		switch op {
+		case STOP:
+			return 0, nil
+		case PUSH:
+			if m.pc+8 > len(m.code) {
+				return 0, fmt.Errorf("%w: incomplete push", errExecution)
+			}
+
+			err = m.push(int64(binary.BigEndian.Uint64(m.code[m.pc : m.pc+8])))
+			m.pc += 8
+		case POP:
+			m.pop()
+		case DUP:
+			v := m.pop()
+			m.stack = append(m.stack, v)
+			err = m.push(v)
+		case SWAP:
+			a, b := m.pop(), m.pop()
+			m.stack = append(m.stack, a, b)
+		case ADD:
+			a, b := m.pop(), m.pop()
+			err = m.push(b + a)
+		case SUB:
+			a, b := m.pop(), m.pop()
+			err = m.push(b - a)
+		case MUL:
+			a, b := m.pop(), m.pop()
+			err = m.push(b * a)
+		case DIV, MOD:
+			a, b := m.pop(), m.pop()
+			if a == 0 {
+				return 0, fmt.Errorf("%w: division by zero", errExecution)
+			}
+
+			if op == DIV {
+				err = m.push(b / a)
+			} else {
+				err = m.push(b % a)
+			}
+		case LT:
+			a, b := m.pop(), m.pop()
+			err = m.push(bool64(b < a))
+		case GT:
+			a, b := m.pop(), m.pop()
+			err = m.push(bool64(b > a))
+		case EQ:
+			a, b := m.pop(), m.pop()
+			err = m.push(bool64(b == a))
+		case ISZERO:
+			err = m.push(bool64(m.pop() == 0))
+		case AND:
+			a, b := m.pop(), m.pop()
+			err = m.push(bool64(a != 0 && b != 0))
+		case OR:
+			a, b := m.pop(), m.pop()
+			err = m.push(bool64(a != 0 || b != 0))
+		case JUMP:
+			err = m.jump(m.pop())
+		case JUMPI:
+			dest, cond := m.pop(), m.pop()
+			if cond != 0 {
+				err = m.jump(dest)
+			}
+		case LOAD:
+			err = m.push(m.storage.Get(m.pop()))
+		case STORE:
+			value, key := m.pop(), m.pop()
+			m.storage.Set(key, value)
+		case INPUT:
+			i := m.pop()
+			if 0 > i || i >= int64(len(m.ctx.Input)) {
+				err = m.push(0)
+			} else {
+				err = m.push(m.ctx.Input[i])
+			}
+		case VALUE:
+			err = m.push(m.ctx.Value)
+		case HEIGHT:
+			err = m.push(int64(m.ctx.Height))
+		case RETURN:
+			return m.pop(), nil
+		case REVERT:
+			return 0, ErrReverted
+		}
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return 0, nil
+}
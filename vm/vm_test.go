@@ -0,0 +1,111 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memory map[int64]int64
+
+func (m memory) Get(key int64) int64 {
+	return m[key]
+}
+
+func (m memory) Set(key int64, value int64) {
+	m[key] = value
+}
+
+// counter adds the first input to the value stored at key 0, and returns the sum.
+const counter = `
+PUSH 0
+PUSH 0
+LOAD
+PUSH 0
+INPUT
+ADD
+STORE
+PUSH 0
+LOAD
+RETURN
+`
+
+func TestExecute(t *testing.T) {
+	code, err := Assemble(counter)
+
+	assert.NoError(t, err)
+	assert.NoError(t, Validate(code))
+
+	storage := memory{}
+
+	res, err := Execute(code, Context{Input: []int64{5}, Gas: 1000}, storage)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), res.Return)
+
+	res, _ = Execute(code, Context{Input: []int64{7}, Gas: 1000}, storage)
+
+	assert.Equal(t, int64(12), res.Return)
+	assert.Equal(t, int64(12), storage[0])
+}
+
+func TestExecuteOutOfGas(t *testing.T) {
+	code, _ := Assemble(counter)
+
+	res, err := Execute(code, Context{Input: []int64{5}, Gas: 10}, memory{})
+
+	assert.ErrorIs(t, err, ErrOutOfGas)
+	assert.Equal(t, uint64(10), res.GasUsed)
+}
+
+func TestExecuteLoop(t *testing.T) {
+	// infinite loop; should run out of gas
+	code, _ := Assemble("PUSH 0\nJUMP")
+
+	_, err := Execute(code, Context{Gas: 10000}, memory{})
+
+	assert.ErrorIs(t, err, ErrOutOfGas)
+}
+
+func TestExecuteConditional(t *testing.T) {
+	// revert unless the value is at least 10
+	code, _ := Assemble(`
+VALUE
+PUSH 10
+LT
+PUSH 31
+JUMPI
+PUSH 1
+RETURN
+REVERT
+`)
+
+	res, err := Execute(code, Context{Value: 10, Gas: 100}, memory{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), res.Return)
+
+	_, err = Execute(code, Context{Value: 9, Gas: 100}, memory{})
+
+	assert.ErrorIs(t, err, ErrReverted)
+}
+
+func TestExecuteErrors(t *testing.T) {
+	for _, source := range []string{"ADD", "PUSH 1\nPUSH 0\nDIV", "PUSH 100\nJUMP"} {
+		code, _ := Assemble(source)
+
+		_, err := Execute(code, Context{Gas: 100}, memory{})
+
+		assert.ErrorIs(t, err, errExecution, source)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.Error(t, Validate(nil))
+	assert.Error(t, Validate([]byte{0xff}))
+	assert.Error(t, Validate([]byte{byte(PUSH), 0, 0}))
+
+	_, err := Assemble("PUSH")
+
+	assert.Error(t, err)
+}
@@ -10,10 +10,12 @@ import (
 // Due to time constraints, a wallet is generated on the node via its API.
 
 // Wallet represents the private and public key within the blockchain.
+// The address is used to identify the account of the wallet.
 type Wallet struct {
 	Mnemonic string
 	Priv     string
 	Pub      string
+	Address  string
 }
 
 // CreateWallet creates a new Wallet.
@@ -27,5 +29,6 @@ func CreateWallet(mnemonic string, password string) (*Wallet, error) {
 		Mnemonic: m,
 		Priv:     util.HexEncode(crypto.EncodePrivateKey(priv)),
 		Pub:      util.HexEncode(crypto.EncodePublicKey(pub)),
+		Address:  crypto.Address(pub),
 	}, nil
 }